@@ -2,6 +2,14 @@ package api
 
 import (
 	"testing"
+
+	"github.com/ychengcloud/cre/gen"
+)
+
+// Generate 的签名保持兼容，报告通过 GenerateReport 获取
+var (
+	_ func(*gen.Config, ...gen.Option) error                = Generate
+	_ func(*gen.Config, ...gen.Option) (*gen.Report, error) = GenerateReport
 )
 
 func TestLoad(t *testing.T) {
@@ -4,38 +4,124 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
+	// SQLite 驱动（纯 Go 实现，注册名为 sqlite）
+	_ "modernc.org/sqlite"
+
 	"github.com/ychengcloud/cre"
 	"github.com/ychengcloud/cre/gen"
 	"github.com/ychengcloud/cre/loader"
 	ldsql "github.com/ychengcloud/cre/loader/sql"
+	"github.com/ychengcloud/cre/spec"
 )
 
-func Generate(cfg *gen.Config) error {
-	var loaderInstance cre.Loader
+// Generate generates the files into Config.GenRoot,
+// opts 可设置 hook 等，见 gen.WithSchemaHook
+func Generate(cfg *gen.Config, opts ...gen.Option) error {
+	_, err := GenerateReport(cfg, opts...)
+	return err
+}
+
+// GenerateReport is like Generate, 返回按写入策略跳过的文件和未能保留的保护区域
+func GenerateReport(cfg *gen.Config, opts ...gen.Option) (*gen.Report, error) {
+	return GenerateTo(cfg, nil, opts...)
+}
+
+// GenerateTo generates the files into the sink, sink 为 nil 时写入 Config.GenRoot
+func GenerateTo(cfg *gen.Config, sink gen.Sink, opts ...gen.Option) (*gen.Report, error) {
+	loaderInstance, err := newLoader(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := gen.NewGenerator(cfg, loaderInstance, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if sink != nil {
+		g.Sink = sink
+	}
+	if err := g.Generate(context.Background()); err != nil {
+		return nil, err
+	}
+	return g.Report(), nil
+}
+
+// DryRun renders the files in memory and compares them with the files on disk, 不写入任何文件
+func DryRun(cfg *gen.Config, opts ...gen.Option) (*gen.Plan, error) {
+	loaderInstance, err := newLoader(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := gen.NewGenerator(cfg, loaderInstance, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return g.DryRun(context.Background())
+}
+
+// Snapshot writes the snapshot of the loaded schema to the path,
+// the format (json or yaml) is decided by the file extension.
+func Snapshot(cfg *gen.Config, path string) error {
+	loaderInstance, err := newLoader(cfg)
+	if err != nil {
+		return err
+	}
+
+	g, err := gen.NewGenerator(cfg, loaderInstance)
+	if err != nil {
+		return err
+	}
+	schema, err := g.LoadSchema(context.Background())
+	if err != nil {
+		return err
+	}
 
+	format := spec.SnapshotJSON
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		format = spec.SnapshotYAML
+	}
+	data, err := spec.MarshalSnapshot(schema, format)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func newLoader(cfg *gen.Config) (cre.Loader, error) {
 	dialect := strings.TrimSpace(cfg.Dialect)
 	dsn := strings.TrimSpace(cfg.DSN)
+	ddl := strings.TrimSpace(cfg.DDL)
+	snapshot := strings.TrimSpace(cfg.Snapshot)
 
 	switch dialect {
-	case gen.LoaderMysql, gen.LoaderPostgres:
-		db, err := sql.Open(dialect, dsn)
-		if err != nil {
-			return err
+	case gen.LoaderMysql, gen.LoaderPostgres, gen.LoaderSQLite:
+		// 离线模式: 读取快照或解析 DDL 脚本，不连接数据库
+		if snapshot != "" {
+			return loader.NewSnapshotLoader(dialect, snapshot), nil
 		}
-		drv := ldsql.OpenDB(dialect, db)
-		loaderInstance, err = loader.NewLoader(drv)
+		if ddl != "" {
+			return loader.NewDDLLoader(dialect, ddl), nil
+		}
+		db, err := sql.Open(driverName(dialect), dsn)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		drv := ldsql.OpenDB(dialect, db)
+		return loader.NewLoader(drv, loader.WithSchemas(gen.SearchPath(cfg)...))
 	default:
-		return fmt.Errorf("unsupported loader: %s", dialect)
+		return nil, fmt.Errorf("unsupported loader: %s", dialect)
 	}
+}
 
-	g, err := gen.NewGenerator(cfg, loaderInstance)
-	if err != nil {
-		return err
+// driverName returns the database/sql driver name registered for the dialect.
+func driverName(dialect string) string {
+	if dialect == gen.LoaderSQLite {
+		return "sqlite"
 	}
-	return g.Generate(context.Background())
+	return dialect
 }
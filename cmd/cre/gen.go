@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
@@ -15,7 +16,18 @@ import (
 	"github.com/ychengcloud/cre/gen"
 )
 
-var configPath string
+var (
+	configPath string
+	dryRun     bool
+	summary    bool
+	prune      bool
+	force      bool
+	out        string
+	jobs       int
+
+	// status 输出进度和警告，--out stdout 时为标准错误，避免混入生成的文件
+	status io.Writer = os.Stdout
+)
 
 var generateCmd = &cobra.Command{
 	Use:     "generate [flags]",
@@ -25,9 +37,40 @@ var generateCmd = &cobra.Command{
 		return nil
 	},
 	Run: func(cmd *cobra.Command, args []string) {
+		// dry-run 不写入任何文件，计划总是输出到标准输出
+		if dryRun && out != "" {
+			fmt.Fprintln(os.Stderr, "gen error: --dry-run can not be used with --out")
+			return
+		}
+		if out == "stdout" {
+			status = os.Stderr
+		}
 		cfg := loadConfig(configPath, strings.ToUpper("cre_"))
+		if prune {
+			cfg.Prune = true
+		}
+		if force {
+			cfg.Force = true
+		}
+		if jobs > 0 {
+			cfg.Jobs = jobs
+		}
+
+		if dryRun {
+			if err := printPlan(cfg); err != nil {
+				fmt.Fprintln(os.Stderr, "gen error:", err.Error())
+			}
+			return
+		}
 
-		if cfg.Overwrite {
+		sink, closeSink, err := openSink(out)
+		if err != nil {
+			fmt.Fprintln(status, "gen error:", err.Error())
+			return
+		}
+
+		// 输出到归档或标准输出时不会覆盖已有文件
+		if _, disk := sink.(*gen.DiskSink); cfg.Overwrite && (sink == nil || disk) {
 			prompt := &survey.Confirm{
 				Message: `[Warning]
 The overwrite flag (Overwrite is True) is specified in the configuration file. If Yes is selected, the generated file will overwrite the existing file. 
@@ -42,7 +85,7 @@ Are you sure to continue?
 			err := survey.AskOne(prompt, &overwrite)
 
 			if err != nil {
-				fmt.Println(err.Error())
+				fmt.Fprintln(status, err.Error())
 				return
 			}
 
@@ -52,22 +95,127 @@ Are you sure to continue?
 
 		}
 
-		if err := api.Generate(cfg); err != nil {
-			fmt.Println("gen error:", err.Error())
+		report, err := api.GenerateTo(cfg, sink)
+		if err := closeSink(err); err != nil {
+			fmt.Fprintln(status, "gen error:", err.Error())
 			return
 		}
-		fmt.Println("Done")
+		for _, path := range report.Skipped {
+			fmt.Fprintln(status, "skip:", path)
+		}
+		for _, path := range report.Pruned {
+			fmt.Fprintln(status, "delete:", path)
+		}
+		printWarnings(report)
+		if n := len(report.Cached); n > 0 {
+			fmt.Fprintf(status, "%d files unchanged since last generation, use --force to regenerate them\n", n)
+		}
+		fmt.Fprintln(status, "Done")
 	},
 }
 
 func init() {
 	generateCmd.Flags().StringVarP(&configPath, "config", "c", "./config.yml", "config file path")
+	generateCmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the changes to stdout without writing any file, can not be used with --out")
+	generateCmd.Flags().BoolVar(&prune, "prune", false, "delete the files which are no longer generated by the current schema")
+	generateCmd.Flags().BoolVar(&force, "force", false, "regenerate all files even if their inputs (schema, templates and config) did not change since last generation, needed after changing custom template funcs or hooks")
+	generateCmd.Flags().StringVar(&out, "out", "", "output of the generated files: dir:<path>, zip:<file>, tar:<file> or stdout, default is genRoot")
+	generateCmd.Flags().IntVarP(&jobs, "jobs", "j", 0, "number of templates rendered and formatted concurrently, default is the number of CPUs")
+	generateCmd.Flags().BoolVar(&summary, "summary", false, "with --dry-run, print the summary only instead of the diffs")
 
 	cobra.OnInitialize()
 	rootCmd.AddCommand(generateCmd)
 
 }
 
+// openSink opens the sink of the --out flag, 为空时返回 nil 即写入 genRoot
+// 返回的 close 函数在生成失败时删除不完整的归档文件
+func openSink(out string) (gen.Sink, func(error) error, error) {
+	nop := func(err error) error { return err }
+	if out == "" {
+		return nil, nop, nil
+	}
+	if out == "stdout" {
+		return gen.NewStdoutSink(os.Stdout), nop, nil
+	}
+
+	kind, path, ok := strings.Cut(out, ":")
+	if !ok || path == "" {
+		return nil, nil, fmt.Errorf("invalid output %q", out)
+	}
+	switch kind {
+	case "dir":
+		return gen.NewDiskSink(path), nop, nil
+	case "zip", "tar":
+		f, err := os.Create(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		closeFn := func(err error) error {
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				os.Remove(path)
+			}
+			return err
+		}
+		if kind == "zip" {
+			return gen.NewZipSink(f), closeFn, nil
+		}
+		return gen.NewTarSink(f), closeFn, nil
+	default:
+		return nil, nil, fmt.Errorf("invalid output %q", out)
+	}
+}
+
+// printWarnings prints the files need to be handled by hand
+func printWarnings(report *gen.Report) {
+	for _, d := range report.Diagnostics {
+		fmt.Fprintln(status, "warning:", d)
+	}
+	for _, path := range report.Edited {
+		fmt.Fprintln(status, "warning: modified since last generation:", path)
+	}
+	pruned := make(map[string]bool, len(report.Pruned))
+	for _, path := range report.Pruned {
+		pruned[path] = true
+	}
+	for _, path := range report.Stale {
+		if !pruned[path] {
+			fmt.Fprintln(status, "warning: no longer generated:", path)
+		}
+	}
+	// 模板中已不存在的保护区域，输出其内容以便手工迁移
+	for _, r := range report.Orphaned {
+		fmt.Fprintf(status, "orphaned region %q in %s:\n%s", r.Name, r.Path, r.Content)
+	}
+}
+
+// printPlan prints the unified diffs and the summary of the changes
+func printPlan(cfg *gen.Config) error {
+	plan, err := api.DryRun(cfg)
+	if err != nil {
+		return err
+	}
+	for _, c := range plan.Changes {
+		if summary {
+			fmt.Printf("%-10s %s\n", c.Op+":", c.Path)
+			continue
+		}
+		diff, err := c.Diff()
+		if err != nil {
+			return err
+		}
+		fmt.Print(diff)
+	}
+	printWarnings(plan.Report)
+	fmt.Printf("%d created, %d modified, %d unchanged, %d skipped, %d deleted\n",
+		plan.Count(gen.ChangeCreated), plan.Count(gen.ChangeModified), plan.Count(gen.ChangeUnchanged),
+		plan.Count(gen.ChangeSkipped), plan.Count(gen.ChangeDeleted))
+	return nil
+}
+
 func loadConfig(path string, prefix string) *gen.Config {
 	var (
 		v = viper.New()
@@ -86,27 +234,27 @@ func loadConfig(path string, prefix string) *gen.Config {
 	//读取默认配置
 	v.SetConfigName(string(path + ".template"))
 	if err := v.ReadInConfig(); err == nil {
-		fmt.Printf("use config file -> %s\n", v.ConfigFileUsed())
+		fmt.Fprintf(status, "use config file -> %s\n", v.ConfigFileUsed())
 		if err := v.Unmarshal(conf); err != nil {
-			fmt.Printf("unmarshal conf failed, err:%s \n", err)
+			fmt.Fprintf(status, "unmarshal conf failed, err:%s \n", err)
 			os.Exit(1)
 		}
 	} else {
-		fmt.Printf("Can't read default config file -> %s\n", v.ConfigFileUsed())
+		fmt.Fprintf(status, "Can't read default config file -> %s\n", v.ConfigFileUsed())
 		os.Exit(1)
 	}
 
 	//读取应用配置
 	v.SetConfigName(string(path))
 	if err := v.ReadInConfig(); err == nil {
-		fmt.Printf("use config file -> %s\n", v.ConfigFileUsed())
+		fmt.Fprintf(status, "use config file -> %s\n", v.ConfigFileUsed())
 	} else {
-		fmt.Printf("unmarshal conf failed, err:%s \n", err)
+		fmt.Fprintf(status, "unmarshal conf failed, err:%s \n", err)
 		os.Exit(1)
 	}
 
 	if err := v.Unmarshal(conf); err != nil {
-		fmt.Printf("unmarshal conf failed, err:%s \n", err)
+		fmt.Fprintf(status, "unmarshal conf failed, err:%s \n", err)
 		os.Exit(1)
 	}
 
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ychengcloud/cre/api"
+)
+
+var snapshotOutput string
+
+var snapshotCmd = &cobra.Command{
+	Use:     "snapshot [flags]",
+	Short:   "dump the database schema to a snapshot file",
+	Example: `cre snapshot -c ./config -o ./schema.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig(configPath, strings.ToUpper("cre_"))
+
+		if err := api.Snapshot(cfg, snapshotOutput); err != nil {
+			fmt.Println("snapshot error:", err.Error())
+			return
+		}
+		fmt.Println("Done")
+	},
+}
+
+func init() {
+	snapshotCmd.Flags().StringVarP(&configPath, "config", "c", "./config.yml", "config file path")
+	snapshotCmd.Flags().StringVarP(&snapshotOutput, "output", "o", "./schema.json", "snapshot file path, json or yaml")
+
+	rootCmd.AddCommand(snapshotCmd)
+}
@@ -1,5 +1,11 @@
 package gen
 
+import (
+	"sort"
+
+	"github.com/ychengcloud/cre/spec"
+)
+
 type Attr struct {
 	name  string
 	value any
@@ -16,3 +22,18 @@ func (a *Attr) Value() any {
 func NewAttr(name string, value any) *Attr {
 	return &Attr{name, value}
 }
+
+// newAttrs returns the attributes of the config sorted by name, 保证合并后的 schema 与顺序无关
+func newAttrs(attrs map[string]any) []spec.Attribute {
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var as []spec.Attribute
+	for _, name := range names {
+		as = append(as, NewAttr(name, attrs[name]))
+	}
+	return as
+}
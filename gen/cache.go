@@ -0,0 +1,375 @@
+package gen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io/fs"
+	"sort"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/ychengcloud/cre/spec"
+)
+
+// 增量生成: manifest 中记录每个生成文件输入的指纹，包括表的 spec、模板及其调用的模板和相关配置
+// 指纹未改变且文件自上次生成后未被修改时，已有内容即为生成结果，跳过渲染和格式化，也不再写入
+// Config.Force 为 true 时忽略已有的指纹，全部重新生成
+//
+// multi 模式只包含表及其关联的表，模板中使用 .Schema 或 .Generator (.Generator.Template 除外)时包含整个 schema
+// WithFuncMap 中的函数和 hook 的实现不在指纹中，修改后需要 Force
+
+// cacheVersion 指纹的计算方式或生成逻辑改变时递增，使已有的指纹全部失效
+const cacheVersion = 1
+
+// fingerprints are the hashes of the inputs of the templates.
+type fingerprints struct {
+	config    string                 // 影响模板输出的配置
+	schema    string                 // 整个 schema，single 和 namespace 模式的数据
+	tables    map[*spec.Table]string // 表及其关联的表
+	templates map[string]string      // 模板及其调用的模板
+	global    map[string]bool        // 模板或其调用的模板使用了整个 schema
+}
+
+// fingerprints computes the hashes of the config, the schema and the templates,
+// 表的 spec 无法序列化(如 attrs 中的值)时返回 nil，不使用增量生成
+func (g *Generator) fingerprints() *fingerprints {
+	snapshot, err := spec.NewSnapshot(g.schema)
+	if err != nil {
+		return nil
+	}
+	fp := &fingerprints{
+		tables:    make(map[*spec.Table]string),
+		templates: make(map[string]string),
+		global:    make(map[string]bool),
+	}
+
+	// 表的顺序与 Schema.Tables 相同
+	sums := make(map[*spec.Table]string)
+	for i, t := range g.schema.Tables() {
+		data, err := json.Marshal(snapshot.Schema.Tables[i])
+		if err != nil {
+			return nil
+		}
+		sums[t] = checksum(data)
+	}
+	for _, t := range g.schema.Tables() {
+		h := sha256.New()
+		fmt.Fprintln(h, sums[t])
+		for _, rt := range g.relatedTables(t) {
+			fmt.Fprintln(h, rt.QualifiedName(), sums[rt])
+		}
+		fp.tables[t] = hex.EncodeToString(h.Sum(nil))
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil
+	}
+	fp.schema = checksum(data)
+
+	h := sha256.New()
+	c := g.Cfg
+	if err := writeJSON(h, c.Project, c.Package, c.Header, c.Dialect, c.Delim, c.Attrs); err != nil {
+		return nil
+	}
+	fp.config = hex.EncodeToString(h.Sum(nil))
+
+	for path := range g.templates {
+		fp.templates[path], fp.global[path] = g.templateSum(path)
+	}
+	return fp
+}
+
+// relatedTables returns the tables referenced by the relations and the foreign keys of t,
+// 模板中通常会使用关联表的字段，其他表的修改不影响 t 的指纹
+func (g *Generator) relatedTables(t *spec.Table) []*spec.Table {
+	seen := map[*spec.Table]bool{t: true}
+	var tables []*spec.Table
+	add := func(rt *spec.Table) {
+		if rt != nil && !seen[rt] {
+			seen[rt] = true
+			tables = append(tables, rt)
+		}
+	}
+	for _, f := range t.Fields() {
+		if f.Rel == nil {
+			continue
+		}
+		add(f.Rel.RefTable)
+		if f.Rel.JoinTable != nil {
+			add(g.schema.Table(f.Rel.JoinTable.Name))
+		}
+	}
+	for _, fk := range t.ForeignKeys {
+		add(fk.RefTable)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].QualifiedName() < tables[j].QualifiedName() })
+	return tables
+}
+
+// templateSum returns the hash of the template, the templates it calls by .Generator.Template and the libraries,
+// 模板名称不是字符串常量时无法确定，包含所有模板; global 为 true 表示其中的模板使用了整个 schema
+func (g *Generator) templateSum(path string) (sum string, global bool) {
+	seen := make(map[string]bool)
+	all := false
+	var visit func(path string)
+	visit = func(path string) {
+		if seen[path] || all {
+			return
+		}
+		seen[path] = true
+		t, ok := g.templates[path]
+		if !ok {
+			return
+		}
+		deps, ok := templateDeps(t)
+		if !ok {
+			all = true
+			return
+		}
+		for _, dep := range deps {
+			visit(dep)
+		}
+	}
+	visit(path)
+	for _, p := range g.libraries {
+		seen[p] = true
+	}
+	if all {
+		for p := range g.templates {
+			seen[p] = true
+		}
+	}
+
+	paths := make([]string, 0, len(seen))
+	for p := range seen {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	h := sha256.New()
+	for _, p := range paths {
+		fmt.Fprintln(h, p, g.sums[p])
+		if t, ok := g.templates[p]; ok && !global {
+			global = usesSchema(t)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), global
+}
+
+// templateDeps returns the names passed to the Template method in t, 名称不是字符串常量时 ok 为 false
+//
+//	{{ .Generator.Template "import/fields.tmpl" .Fields }}
+func templateDeps(t *template.Template) (deps []string, ok bool) {
+	ok = true
+	walkTemplate(t, func(node parse.Node) {
+		n, isCmd := node.(*parse.CommandNode)
+		if !isCmd || len(n.Args) == 0 || !callsTemplate(n.Args[0]) {
+			return
+		}
+		if len(n.Args) > 1 {
+			if s, isStr := n.Args[1].(*parse.StringNode); isStr {
+				deps = append(deps, s.Text)
+				return
+			}
+		}
+		ok = false
+	})
+	return deps, ok
+}
+
+// walkTemplate calls fn for each node of t and the templates associated with t, 父节点先于子节点
+func walkTemplate(t *template.Template, fn func(node parse.Node)) {
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, c := range n.Nodes {
+				walk(c)
+			}
+			return
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+		}
+		fn(node)
+		switch n := node.(type) {
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.IfNode:
+			walk(&n.BranchNode)
+		case *parse.RangeNode:
+			walk(&n.BranchNode)
+		case *parse.WithNode:
+			walk(&n.BranchNode)
+		case *parse.BranchNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.TemplateNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			for _, c := range n.Cmds {
+				walk(c)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		case *parse.ChainNode:
+			walk(n.Node)
+		}
+	}
+	for _, tpl := range t.Templates() {
+		if tpl.Tree != nil {
+			walk(tpl.Tree.Root)
+		}
+	}
+}
+
+// usesSchema reports whether t uses the whole schema by .Schema or .Generator, 如 .Generator.Schema.Tables
+// 调用模板的 .Generator.Template 已包含在 templateDeps 中，{{ $g := .Generator }} 赋值的变量按 .Generator 处理
+func usesSchema(t *template.Template) bool {
+	generators := make(map[string]bool)
+	assigned := make(map[parse.Node]bool)
+	uses := false
+	check := func(idents []string) {
+		for i, ident := range idents {
+			switch {
+			case ident == "Schema":
+				uses = true
+			case ident == "Generator" && (i+1 == len(idents) || idents[i+1] != "Template"):
+				uses = true
+			}
+		}
+	}
+	walkTemplate(t, func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ActionNode:
+			pipe := n.Pipe
+			if len(pipe.Decl) != 1 || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+				return
+			}
+			if f, ok := pipe.Cmds[0].Args[0].(*parse.FieldNode); ok && len(f.Ident) == 1 && f.Ident[0] == "Generator" {
+				generators[pipe.Decl[0].Ident[0]] = true
+				assigned[f] = true
+			}
+		case *parse.FieldNode:
+			if !assigned[n] {
+				check(n.Ident)
+			}
+		case *parse.ChainNode:
+			check(n.Field)
+		case *parse.VariableNode:
+			if generators[n.Ident[0]] {
+				check(append([]string{"Generator"}, n.Ident[1:]...))
+			} else {
+				check(n.Ident[1:])
+			}
+		}
+	})
+	return uses
+}
+
+// callsTemplate reports whether the node is the Template method, 如 .Generator.Template、$g.Template
+func callsTemplate(node parse.Node) bool {
+	var idents []string
+	switch n := node.(type) {
+	case *parse.FieldNode:
+		idents = n.Ident
+	case *parse.VariableNode:
+		idents = n.Ident[1:]
+	case *parse.ChainNode:
+		idents = n.Field
+	}
+	return len(idents) > 0 && idents[len(idents)-1] == "Template"
+}
+
+// fingerprint returns the fingerprint of the rendering, 无法计算时为空
+func (fp *fingerprints) fingerprint(job renderJob) string {
+	if fp == nil {
+		return ""
+	}
+	h := sha256.New()
+	t := job.tpl
+	fmt.Fprintln(h, cacheVersion, fp.config, fp.templates[t.Path])
+	fmt.Fprintln(h, t.Path, t.GenPath, t.Format, t.Mode, t.M2M)
+
+	// 关联、关联表和分组的数据可访问整个 schema
+	switch job.data.(type) {
+	case *relationData, *joinData, *groupData:
+		fmt.Fprintln(h, fp.schema)
+	case *tableData:
+		if fp.global[t.Path] {
+			fmt.Fprintln(h, fp.schema)
+		}
+	}
+
+	switch d := job.data.(type) {
+	case *tableData:
+		fmt.Fprintln(h, fp.tables[d.Table])
+		if d.M2MField != nil {
+			fmt.Fprintln(h, d.M2MField.Name)
+		}
+	case *schemaData:
+		fmt.Fprintln(h, fp.schema, d.Namespace)
+	case *enumData:
+		// 枚举可能被多个表使用
+		fmt.Fprintln(h, fp.schema, d.Name)
+	case *relationData:
+		fmt.Fprintln(h, fp.tables[d.Table], fp.tables[d.RefTable], d.Field.Name)
+	case *joinData:
+		fmt.Fprintln(h, fp.tables[d.Table])
+	case *groupData:
+		fmt.Fprintln(h, t.Group, d.Group)
+		for _, table := range d.Tables {
+			fmt.Fprintln(h, fp.tables[table])
+		}
+	default:
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cached reports whether the file is unchanged since the last generation, 此时使用已有的内容
+func (g *Generator) cached(f *file, last map[string]*ManifestFile) (bool, error) {
+	if g.Cfg.Force || f.fingerprint == "" {
+		return false, nil
+	}
+	mf := last[f.path]
+	if mf == nil || mf.Fingerprint != f.fingerprint || mf.Template != f.template || mf.Hash == "" {
+		return false, nil
+	}
+	existing, err := g.Sink.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("read file %q: %w", f.path, err)
+	}
+	// 手工修改过的文件需要重新合并保护区域
+	if checksum(existing) != mf.Hash {
+		return false, nil
+	}
+	f.content = existing
+	f.cached = true
+	return true, nil
+}
+
+func writeJSON(h hash.Hash, values ...any) error {
+	enc := json.NewEncoder(h)
+	for _, v := range values {
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
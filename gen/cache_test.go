@@ -0,0 +1,127 @@
+package gen
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+	"text/template"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ychengcloud/cre/spec"
+)
+
+func TestCache(t *testing.T) {
+	r := require.New(t)
+
+	templates := fstest.MapFS{
+		"table.tmpl":  {Data: []byte(`{{ .Table.Name }}: {{ .Generator.Template "fields.tmpl" .Table }}`)},
+		"fields.tmpl": {Data: []byte("{{ range .Fields }}{{ .Name }} {{ end }}\n")},
+		"tables.tmpl": {Data: []byte("{{ range .Tables }}{{ .Name }}\n{{ end }}")},
+		// multi 模式中使用整个 schema
+		"schema.tmpl": {Data: []byte("{{ .Table.Name }}:{{ range .Schema.Tables }} {{ len .Fields }}{{ end }}\n")},
+	}
+	sink := NewMemSink()
+	run := func(force bool, postFields ...string) *Report {
+		schema := &spec.Schema{Name: "main"}
+		fields := map[string][]string{"user": {"name"}, "post": postFields}
+		for _, name := range []string{"user", "post"} {
+			table := &spec.Table{Name: name}
+			table.AddFields(&spec.Field{Name: "id", Type: &spec.IntegerType{Name: "int", Size: 64}, PrimaryKey: true})
+			for _, f := range fields[name] {
+				table.AddFields(&spec.Field{Name: f, Type: &spec.StringType{Name: "text"}})
+			}
+			table.ID = table.GetField("id")
+			schema.AddTables(table)
+		}
+		g, _ := newMemGenerator(t, &Config{
+			Force: force,
+			Templates: []*Template{
+				{Path: "table.tmpl", Format: "{{ .Table.Name }}.txt", Mode: TplModeMulti},
+				{Path: "tables.tmpl", Format: "tables.txt"},
+				{Path: "schema.tmpl", GenPath: "schema", Format: "{{ .Table.Name }}.txt", Mode: TplModeMulti},
+			},
+		}, schema, templates)
+		g.Sink = sink
+		r.NoError(g.Generate(context.Background()))
+		return g.Report()
+	}
+
+	report := run(false, "title")
+	r.Empty(report.Cached)
+	r.Equal("post: id title \n", string(sink.FS["post.txt"].Data))
+
+	report = run(false, "title")
+	r.Equal([]string{"user.txt", "post.txt", "tables.txt", "schema/user.txt", "schema/post.txt"}, report.Cached)
+
+	// 只有修改的表和使用整个 schema 的文件重新生成
+	report = run(false, "title", "body")
+	r.Equal([]string{"user.txt"}, report.Cached)
+	r.Equal("post: id title body \n", string(sink.FS["post.txt"].Data))
+	r.Equal("user: 2 3\n", string(sink.FS["schema/user.txt"].Data))
+
+	// 修改模板调用的模板
+	templates["fields.tmpl"] = &fstest.MapFile{Data: []byte("{{ range .Fields }}[{{ .Name }}]{{ end }}\n")}
+	report = run(false, "title", "body")
+	r.Equal([]string{"tables.txt", "schema/user.txt", "schema/post.txt"}, report.Cached)
+	r.Equal("user: [id][name]\n", string(sink.FS["user.txt"].Data))
+
+	// 手工修改的文件重新生成
+	r.NoError(sink.WriteFiles([]*SinkFile{{Path: "user.txt", Content: []byte("edited\n")}}))
+	report = run(false, "title", "body")
+	r.Equal([]string{"post.txt", "tables.txt", "schema/user.txt", "schema/post.txt"}, report.Cached)
+	r.Equal([]string{"user.txt"}, report.Edited)
+	r.Equal("user: [id][name]\n", string(sink.FS["user.txt"].Data))
+
+	report = run(true, "title", "body")
+	r.Empty(report.Cached)
+	report = run(false, "title", "body")
+	r.Len(report.Cached, 5)
+}
+
+func TestUsesSchema(t *testing.T) {
+	tests := []struct {
+		text string
+		uses bool
+	}{
+		{`{{ .Name }}{{ range .Fields }}{{ .Name }}{{ end }}`, false},
+		{`{{ .Generator.Template "a.tmpl" . }}`, false},
+		{`{{ $g := .Generator }}{{ range .Fields }}{{ $g.Template "b.tmpl" . }}{{ end }}`, false},
+		{`{{ .Schema.Name }}`, true},
+		{`{{ range $.Table.Schema.Tables }}{{ end }}`, true},
+		{`{{ .Generator.Schema.Tables }}`, true},
+		{`{{ $g := .Generator }}{{ $g.Cfg.Package }}`, true},
+		{`{{ define "x" }}{{ template "y" .Generator }}{{ end }}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			tpl, err := template.New("t").Parse(tt.text)
+			require.NoError(t, err)
+			require.Equal(t, tt.uses, usesSchema(tpl))
+		})
+	}
+}
+
+func TestTemplateDeps(t *testing.T) {
+	tests := []struct {
+		text string
+		deps []string
+		ok   bool
+	}{
+		{`{{ .Name }}`, nil, true},
+		{`{{ .Generator.Template "a.tmpl" . }}`, []string{"a.tmpl"}, true},
+		{`{{ $g := .Generator }}{{ range .Fields }}{{ $g.Template "b.tmpl" . }}{{ end }}`, []string{"b.tmpl"}, true},
+		{`{{ define "x" }}{{ if .A }}{{ (.Generator).Template "c.tmpl" . | upper }}{{ end }}{{ end }}`, []string{"c.tmpl"}, true},
+		{`{{ .Generator.Template .Name . }}`, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			r := require.New(t)
+			tpl, err := template.New("t").Funcs(template.FuncMap{"upper": func(s string) string { return s }}).Parse(tt.text)
+			r.NoError(err)
+			deps, ok := templateDeps(tpl)
+			r.Equal(tt.ok, ok)
+			r.Equal(tt.deps, deps)
+		})
+	}
+}
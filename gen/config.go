@@ -1,5 +1,7 @@
 package gen
 
+import "fmt"
+
 const (
 	DefaultIDName = "id"
 )
@@ -7,11 +9,24 @@ const (
 const (
 	LoaderMysql    = "mysql"
 	LoaderPostgres = "postgres"
+	LoaderSQLite   = "sqlite3"
+)
+
+const (
+	TplModeSingle    = "single"
+	TplModeMulti     = "multi"
+	TplModeNamespace = "namespace"
+	TplModeEnum      = "enum"
+	TplModeRelation  = "relation"
+	TplModeJoin      = "join"
+	TplModeGroup     = "group"
 )
 
+// 生成文件的写入策略
 const (
-	TplModeSingle = "single"
-	TplModeMulti  = "multi"
+	WriteAlways    = "always"    // 总是覆盖
+	WriteIfMissing = "ifMissing" // 文件不存在时写入，适用于只生成一次、之后手工维护的脚手架文件
+	WriteNever     = "never"     // 从不写入
 )
 
 type Type string
@@ -43,16 +58,38 @@ type Config struct {
 	Header    string         `yaml:"header" mapstructure:"header"`
 	Dialect   string         `yaml:"dialect" mapstructure:"dialect"` // the name of the dialect.
 	DSN       string         `yaml:"dsn" mapstructure:"dsn"`
+	DDL       string         `yaml:"ddl" mapstructure:"ddl"`           // DDL 脚本路径(支持通配符)，设置后不再连接数据库
+	Snapshot  string         `yaml:"snapshot" mapstructure:"snapshot"` // schema 快照文件路径(json/yaml)，设置后不再连接数据库
 	Overwrite bool           `yaml:"overwrite" mapstructure:"overwrite"`
-	Delim     Delim          `yaml:"delim" mapstructure:"delim"`     // 模板变量标识符
-	Root      string         `yaml:"root" mapstructure:"root"`       // 模板根目录
-	GenRoot   string         `yaml:"genRoot" mapstructure:"genRoot"` // 生成根目录
-	Attrs     map[string]any `yaml:"attrs" mapstructure:"attrs"`     // 其他配置项
+	Prune     bool           `yaml:"prune" mapstructure:"prune"`         // 删除当前 schema 不再生成的文件，见 ManifestPath
+	Force     bool           `yaml:"force" mapstructure:"force"`         // 忽略上次生成的指纹，重新生成所有文件
+	Delim     Delim          `yaml:"delim" mapstructure:"delim"`         // 模板变量标识符
+	Root      string         `yaml:"root" mapstructure:"root"`           // 模板根目录，其中的非 .tmpl 文件原样复制到 GenRoot
+	Libraries []string       `yaml:"libraries" mapstructure:"libraries"` // 模板库目录，相对于 Root，其中模板的 {{ define }} 可在所有模板中使用
+	GenRoot   string         `yaml:"genRoot" mapstructure:"genRoot"`     // 生成根目录
+	Attrs     map[string]any `yaml:"attrs" mapstructure:"attrs"`         // 其他配置项
+
+	// DefaultWrite 未设置 Template.Write 的模板、静态文件和插件的写入策略，为空时为 always
+	// 已生成的项目希望只写入不存在的文件时设置为 ifMissing，Overwrite 只影响命令行的覆盖确认
+	DefaultWrite string `yaml:"defaultWrite" mapstructure:"defaultWrite"`
+
+	// Schemas 需要加载的 Postgres schema 列表，为空时取 DSN 中的 search_path，默认为 public
+	// 多个 schema 时表名可使用限定名称，如 billing.invoice
+	Schemas []string `yaml:"schemas" mapstructure:"schemas"`
+
+	// Jobs 并发渲染和格式化的任务数，默认为 CPU 数，生成结果与顺序执行时相同
+	Jobs int `yaml:"jobs" mapstructure:"jobs"`
+
+	// InferRelations 根据外键自动推断关联关系，手写的 Relation 配置优先
+	InferRelations bool `yaml:"inferRelations" mapstructure:"inferRelations"`
 
 	// Templates 所有的 Template Path 需要保证唯一，实际模板文件路径仅为更好的组织文件
 	Templates []*Template `yaml:"templates" mapstructure:"templates"`
 
 	Tables []*Table `yaml:"tables" mapstructure:"tables"`
+
+	// Plugins 外部生成器，接收合并后的 schema，生成的文件与模板生成的文件一起写入，见 Plugin
+	Plugins []*Plugin `yaml:"plugins" mapstructure:"plugins"`
 }
 
 type Delim struct {
@@ -62,14 +99,40 @@ type Delim struct {
 
 type Template struct {
 	Path    string `yaml:"path" mapstructure:"path"`       // Path 模板相对路径,相对于Root
-	GenPath string `yaml:"genPath" mapstructure:"genPath"` // GenPath 生成路径，相对于GenRoot
+	GenPath string `yaml:"genPath" mapstructure:"genPath"` // GenPath 生成路径，相对于GenRoot，可使用与 Format 相同的占位符
 	Format  string `yaml:"format" mapstructure:"format"`   // Format 生成文件名格式
-	// Mode 生成模式, 可选值: "single", "multi"
+	// Mode 生成模式, 可选值: "single", "multi", "namespace", "enum", "relation", "join", "group"
 	// 默认: single 模式下, 所有表数据生成一个文件
 	// multi 模式下, 每个表数据生成一个文件
+	// namespace 模式下, 每个 schema(如 Postgres 的 billing) 生成一个文件
+	// enum 模式下, 每个枚举类型生成一个文件，数据见 enumData
+	// relation 模式下, 每条关联生成一个文件，数据见 relationData
+	// join 模式下, 每个关联表生成一个文件，数据见 joinData
+	// group 模式下, 按 Group 分组，每组生成一个文件，数据见 groupData
 	Mode string `yaml:"mode" mapstructure:"mode"`
+	// Group group 模式的分组方式, 可选值: "attr:<name>", "prefix", "prefix:<sep>"
+	Group string `yaml:"group" mapstructure:"group"`
 	// M2M 是否 Many To Many 模板
 	M2M bool `yaml:"m2m" mapstructure:"m2m"`
+	// Write 写入策略, 可选值: "always", "ifMissing", "never"
+	// 默认: Config.DefaultWrite, 未设置时为 always
+	Write string `yaml:"write" mapstructure:"write"`
+}
+
+// writePolicy returns the write policy of the template, 未设置时使用 defaultWrite
+func (t *Template) writePolicy(defaultWrite string) (string, error) {
+	write := t.Write
+	if write == "" {
+		write = defaultWrite
+	}
+	switch write {
+	case "":
+		return WriteAlways, nil
+	case WriteAlways, WriteIfMissing, WriteNever:
+		return write, nil
+	default:
+		return "", fmt.Errorf("template %s: unknown write policy %q", t.Path, write)
+	}
 }
 
 type Table struct {
@@ -105,6 +168,8 @@ type Relation struct {
 	RefField  string         `yaml:"ref_field" mapstructure:"ref_field"`
 	JoinTable *JoinTable     `yaml:"join_table" mapstructure:"join_table"` // 当 Type 为 ManyToMany 时, JoinTable 不为空
 	Inverse   bool           `yaml:"inverse" mapstructure:"inverse"`
+	OnDelete  string         `yaml:"on_delete" mapstructure:"on_delete"` // 引用动作，默认取数据库中的外键定义
+	OnUpdate  string         `yaml:"on_update" mapstructure:"on_update"`
 	Attrs     map[string]any `yaml:"attrs" mapstructure:"attrs"` // 其他配置项
 }
 
@@ -17,13 +17,16 @@ import (
 
 var (
 	Funcs = template.FuncMap{
-		"receiver": receiver,
-		"snake":    snake,
-		"pascal":   pascal,
-		"camel":    camel,
-		"plural":   plural,
-		"singular": singular,
-		"attrs":    attrs,
+		// addImport imports 输出占位符，渲染完成后替换，见 resolveImports
+		"receiver":  receiver,
+		"addImport": addImport,
+		"imports":   importsFunc,
+		"snake":     snake,
+		"pascal":    pascal,
+		"camel":     camel,
+		"plural":    plural,
+		"singular":  singular,
+		"attrs":     attrs,
 	}
 	rules    = ruleset()
 	acronyms = make(map[string]struct{})
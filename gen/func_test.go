@@ -16,3 +16,10 @@ func TestFuncs(t *testing.T) {
 	require.Equal(t, "fm", receiver([]string{"fmt", "f"}, "fmt"))
 	require.Equal(t, "_fmt", receiver([]string{"fmt", "f", "fm"}, "fmt"))
 }
+
+func TestFileNameReceiver(t *testing.T) {
+	// 文件名模板使用 Funcs，receiver 直接返回名称
+	name, err := fileName(`{{ receiver nil "user_group" }}.go`, nil)
+	require.NoError(t, err)
+	require.Equal(t, "ug.go", name)
+}
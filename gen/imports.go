@@ -0,0 +1,153 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"text/template"
+	"text/template/parse"
+)
+
+// 模板只渲染一次，import 和 receiver 先输出占位符，渲染完成后根据生成文件的 import 替换
+//
+//	{{ imports }}
+//	{{ addImport "github.com/google/uuid" }}
+//	func ({{ receiver .ImportPkg .Name }} *{{ pascal .Name }}) ...
+//
+// 占位符中的名称以十六进制编码，保证生成的内容仍是合法的 Go 标识符
+//
+// 占位符只能直接输出，模板中使用 .ImportPkg 或 receiver 的结果时(如 {{ if eq (receiver .ImportPkg .Name) "u" }})
+// 仍按之前的方式渲染两次: 第一次渲染得到生成文件的 import 填充 ImportPkg，第二次渲染时 receiver 直接返回名称，见 eagerImports
+var (
+	receiverRe         = regexp.MustCompile(`__cre_receiver_([0-9a-f]*)__`)
+	addImportRe        = regexp.MustCompile(`__cre_import_([0-9a-f]*)__`)
+	importsPlaceholder = []byte("__cre_imports__")
+)
+
+// renderFuncs 只用于渲染模板，receiver 输出占位符; 文件名等其他模板使用 Funcs 中的 receiver
+var renderFuncs = template.FuncMap{"receiver": deferredReceiver}
+
+// deferredReceiver returns the placeholder of the receiver name, importPkg 仅为兼容旧模板保留
+func deferredReceiver(importPkg []string, s string) string {
+	return "__cre_receiver_" + hex.EncodeToString([]byte(s)) + "__"
+}
+
+// eagerImports reports whether t uses .ImportPkg or the result of receiver other than printing it,
+// 此时占位符会参与比较等运算，需要渲染两次
+func eagerImports(t *template.Template) bool {
+	printed := make(map[parse.Node]bool)
+	eager := false
+	walkTemplate(t, func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ActionNode:
+			// {{ receiver .ImportPkg .Name }} 直接输出
+			if len(n.Pipe.Decl) == 0 && len(n.Pipe.Cmds) == 1 && callsReceiver(n.Pipe.Cmds[0]) {
+				cmd := n.Pipe.Cmds[0]
+				printed[cmd] = true
+				for _, arg := range cmd.Args[1:] {
+					printed[arg] = true
+				}
+			}
+		case *parse.CommandNode:
+			if callsReceiver(n) && !printed[n] {
+				eager = true
+			}
+		case *parse.FieldNode, *parse.VariableNode, *parse.ChainNode:
+			if usesImportPkg(n) && !printed[n] {
+				eager = true
+			}
+		}
+	})
+	return eager
+}
+
+func callsReceiver(cmd *parse.CommandNode) bool {
+	if len(cmd.Args) == 0 {
+		return false
+	}
+	id, ok := cmd.Args[0].(*parse.IdentifierNode)
+	return ok && id.Ident == "receiver"
+}
+
+func usesImportPkg(node parse.Node) bool {
+	var idents []string
+	switch n := node.(type) {
+	case *parse.FieldNode:
+		idents = n.Ident
+	case *parse.VariableNode:
+		idents = n.Ident[1:]
+	case *parse.ChainNode:
+		idents = n.Field
+	}
+	for _, ident := range idents {
+		if ident == "ImportPkg" {
+			return true
+		}
+	}
+	return false
+}
+
+// addImport registers the import path, 在 {{ imports }} 处输出
+func addImport(path string) string {
+	return "__cre_import_" + hex.EncodeToString([]byte(path)) + "__"
+}
+
+func importsFunc() string {
+	return string(importsPlaceholder)
+}
+
+// resolveImports replaces the placeholders in the rendered content,
+// receiver 与生成文件中 import 的包名冲突时换用其他名称
+func resolveImports(content []byte, goFile bool) ([]byte, error) {
+	var paths []string
+	if addImportRe.Match(content) {
+		seen := make(map[string]bool)
+		for _, m := range addImportRe.FindAllSubmatch(content, -1) {
+			path := string(decodeHex(m[1]))
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+		content = addImportRe.ReplaceAll(content, nil)
+	}
+	if bytes.Contains(content, importsPlaceholder) {
+		content = bytes.ReplaceAll(content, importsPlaceholder, importDecl(paths))
+	}
+
+	if !receiverRe.Match(content) {
+		return content, nil
+	}
+	var pkgs []string
+	if goFile {
+		var err error
+		if pkgs, err = goImportPkgs(bytes.NewReader(content)); err != nil {
+			return nil, err
+		}
+	}
+	return receiverRe.ReplaceAllFunc(content, func(m []byte) []byte {
+		name := decodeHex(receiverRe.FindSubmatch(m)[1])
+		return []byte(receiver(pkgs, string(name)))
+	}), nil
+}
+
+func decodeHex(b []byte) []byte {
+	d, _ := hex.DecodeString(string(b))
+	return d
+}
+
+func importDecl(paths []string) []byte {
+	if len(paths) == 0 {
+		return nil
+	}
+	sort.Strings(paths)
+
+	b := bytes.NewBufferString("import (\n")
+	for _, p := range paths {
+		fmt.Fprintf(b, "\t%q\n", p)
+	}
+	b.WriteString(")\n")
+	return b.Bytes()
+}
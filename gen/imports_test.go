@@ -0,0 +1,89 @@
+package gen
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveImports(t *testing.T) {
+	r := require.New(t)
+
+	tpl := template.Must(template.New("t").Funcs(Funcs).Funcs(renderFuncs).Parse(`package p
+
+{{ imports }}
+{{- range .Names }}
+{{ addImport "fmt" }}{{ addImport "strings" }}
+func ({{ receiver nil . }} *{{ pascal . }}) String() string { return fmt.Sprint(strings.ToUpper("")) }
+{{- end }}
+`))
+	var b bytes.Buffer
+	r.NoError(tpl.Execute(&b, map[string]any{"Names": []string{"fmt", "user", "string_set"}}))
+
+	content, err := resolveImports(b.Bytes(), true)
+	r.NoError(err)
+	r.Equal(`package p
+
+import (
+	"fmt"
+	"strings"
+)
+
+
+func (f *Fmt) String() string { return fmt.Sprint(strings.ToUpper("")) }
+
+func (u *User) String() string { return fmt.Sprint(strings.ToUpper("")) }
+
+func (ss *StringSet) String() string { return fmt.Sprint(strings.ToUpper("")) }
+`, string(content))
+
+	// 非 Go 文件不解析 import
+	content, err = resolveImports([]byte(deferredReceiver(nil, "for")), false)
+	r.NoError(err)
+	r.Equal("f", string(content))
+}
+
+func TestEagerImports(t *testing.T) {
+	tests := []struct {
+		src   string
+		eager bool
+	}{
+		{src: `func ({{ receiver .ImportPkg .Name }} *T)`},
+		{src: `func ({{ receiver nil .Name }} *T)`},
+		{src: `{{ define "recv" }}{{ receiver $.ImportPkg .Name }}{{ end }}`},
+		{src: `{{ $r := receiver .ImportPkg .Name }}{{ $r }}`, eager: true},
+		{src: `{{ if eq (receiver .ImportPkg .Name) "u" }}{{ end }}`, eager: true},
+		{src: `{{ receiver .ImportPkg .Name | printf "%s" }}`, eager: true},
+		{src: `{{ range .ImportPkg }}{{ . }}{{ end }}`, eager: true},
+		{src: `{{ len $.ImportPkg }}`, eager: true},
+	}
+	for _, tt := range tests {
+		tpl := template.Must(template.New("t").Funcs(Funcs).Parse(tt.src))
+		require.Equal(t, tt.eager, eagerImports(tpl), tt.src)
+	}
+}
+
+func TestEagerRender(t *testing.T) {
+	r := require.New(t)
+
+	render := func(src string) map[string]string {
+		g := newTemplateGenerator(r, 2, src)
+		sink := NewMemSink()
+		g.Sink = sink
+		r.NoError(g.Generate(context.Background()))
+		return memFiles(sink)
+	}
+	r.Equal(render(tableTemplate), render(eagerTableTemplate))
+
+	// 使用 .ImportPkg 时仍根据第一次渲染的结果填充
+	files := render(`package multi
+{{ imports }}
+{{ addImport "fmt" }}
+var _ = fmt.Sprint
+// {{ join "," .ImportPkg }}
+`)
+	r.Contains(files["table0.go"], "// fmt\n")
+}
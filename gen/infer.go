@@ -0,0 +1,164 @@
+package gen
+
+import (
+	"strings"
+
+	"github.com/ychengcloud/cre/spec"
+)
+
+// inferRelations 根据外键推断关联关系
+//
+//	外键所在表          => BelongsTo
+//	被引用表            => HasMany，外键字段唯一时为 HasOne
+//	两个外键 + 复合主键 => 关联表，两侧均为 ManyToMany
+//
+// 已经存在的关联（手写配置）或同名字段优先，不会被覆盖
+func inferRelations(s *spec.Schema) {
+	for _, t := range s.Tables() {
+		fks := foreignKeys(s, t)
+		if isJoinTable(t, fks) {
+			inferManyToMany(t, fks[0], fks[1])
+			continue
+		}
+		for _, fk := range fks {
+			inferBelongsTo(t, fk)
+		}
+	}
+}
+
+// foreignKeys returns the single column foreign keys which are still valid after merging,
+// 配置中忽略的表和字段对应的外键不再参与推断
+func foreignKeys(s *spec.Schema, t *spec.Table) []*spec.ForeignKey {
+	fks := make([]*spec.ForeignKey, 0, len(t.ForeignKeys))
+	for _, fk := range t.ForeignKeys {
+		if len(fk.Fields) != 1 || len(fk.RefFields) != 1 || fk.RefTable == nil {
+			continue
+		}
+		if s.Table(fk.RefTable.QualifiedName()) != fk.RefTable {
+			continue
+		}
+		if t.GetField(fk.Fields[0].Name) != fk.Fields[0] || fk.RefTable.GetField(fk.RefFields[0].Name) != fk.RefFields[0] {
+			continue
+		}
+		fks = append(fks, fk)
+	}
+	return fks
+}
+
+// isJoinTable returns true if the table has exactly two foreign keys and a composite primary key
+// which contains both of them.
+func isJoinTable(t *spec.Table, fks []*spec.ForeignKey) bool {
+	if len(t.ForeignKeys) != 2 || len(fks) != 2 {
+		return false
+	}
+	return t.CompositeKey() && fks[0].Fields[0].PrimaryKey && fks[1].Fields[0].PrimaryKey
+}
+
+func inferBelongsTo(t *spec.Table, fk *spec.ForeignKey) {
+	field, rt, refField := fk.Fields[0], fk.RefTable, fk.RefFields[0]
+
+	name := relationName(fk)
+	if !hasRelation(t, field, rt, refField, spec.RelTypeBelongsTo) {
+		addRelation(t, name, &spec.Relation{
+			Type:     spec.RelTypeBelongsTo,
+			Field:    field,
+			RefTable: rt,
+			RefField: refField,
+			OnDelete: fk.OnDelete,
+			OnUpdate: fk.OnUpdate,
+		})
+	}
+
+	if hasRelation(rt, refField, t, field, spec.RelTypeHasOne, spec.RelTypeHasMany) {
+		return
+	}
+	rel := &spec.Relation{
+		Type:     spec.RelTypeHasMany,
+		Field:    refField,
+		RefTable: t,
+		RefField: field,
+		OnDelete: fk.OnDelete,
+		OnUpdate: fk.OnUpdate,
+	}
+	inverse := rules.Pluralize(t.Name)
+	if field.Unique {
+		rel.Type = spec.RelTypeHasOne
+		inverse = t.Name
+	}
+	// 同一表存在多个外键时，以外键名称区分，eg: author_id => author_posts
+	if name != rt.Name {
+		inverse = name + "_" + inverse
+	}
+	addRelation(rt, inverse, rel)
+}
+
+func inferManyToMany(t *spec.Table, fk, refFk *spec.ForeignKey) {
+	t.IsJoinTable = true
+
+	add := func(fk, refFk *spec.ForeignKey, inverse bool) {
+		table, rt := fk.RefTable, refFk.RefTable
+		for _, f := range table.Fields() {
+			if f.RelManyToMany() && f.Rel.RefTable == rt && f.Rel.JoinTable != nil && f.Rel.JoinTable.Name == t.Name {
+				return
+			}
+		}
+		jt := &spec.JoinTable{
+			Name:         t.Name,
+			JoinField:    fk.Fields[0],
+			JoinRefField: refFk.Fields[0],
+		}
+		added := addRelation(table, rules.Pluralize(relationName(refFk)), &spec.Relation{
+			Type:      spec.RelTypeManyToMany,
+			Field:     fk.RefFields[0],
+			RefTable:  rt,
+			RefField:  refFk.RefFields[0],
+			JoinTable: jt,
+			Inverse:   inverse,
+			OnDelete:  fk.OnDelete,
+			OnUpdate:  fk.OnUpdate,
+		})
+		if added && t.JoinTable == nil {
+			t.JoinTable = jt
+		}
+	}
+	add(fk, refFk, false)
+	add(refFk, fk, true)
+}
+
+// relationName returns the relation name of the foreign key.
+//
+//	user_id   => user
+//	author_id => author
+//	owner     => <ref table>
+func relationName(fk *spec.ForeignKey) string {
+	column := fk.Fields[0].Name
+	name := strings.TrimSuffix(column, "_"+fk.RefFields[0].Name)
+	if name == column || name == "" {
+		return fk.RefTable.Name
+	}
+	return name
+}
+
+// hasRelation returns true if the table already has a relation between the given fields.
+func hasRelation(t *spec.Table, field *spec.Field, rt *spec.Table, refField *spec.Field, types ...spec.RelType) bool {
+	for _, f := range t.Fields() {
+		if f.Rel == nil || f.Rel.Field != field || f.Rel.RefTable != rt || f.Rel.RefField != refField {
+			continue
+		}
+		for _, typ := range types {
+			if f.Rel.Type == typ {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// addRelation adds a relation field to the table, 同名字段已存在时忽略
+func addRelation(t *spec.Table, name string, rel *spec.Relation) bool {
+	if t.GetField(name) != nil {
+		return false
+	}
+	t.AddFields(spec.Builder(name).Type(&spec.ObjectType{Name: name}).Rel(rel).Build())
+	return true
+}
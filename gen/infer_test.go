@@ -0,0 +1,208 @@
+package gen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ychengcloud/cre/spec"
+)
+
+// inferSchema returns a schema with foreign keys:
+//
+//	user     { id }
+//	profile  { id, user_id(unique) => user.id }
+//	post     { id, user_id => user.id(on delete cascade), author_id => user.id }
+//	tag      { id }
+//	post_tag { post_id => post.id, tag_id => tag.id, pk(post_id, tag_id) }
+func inferSchema() *spec.Schema {
+	id := func() *spec.Field {
+		return spec.Builder("id").Type(&spec.IntegerType{Name: "int", Size: 32}).PrimaryKey(true).Unique(true).Build()
+	}
+	fk := func(name string) *spec.Field {
+		return spec.Builder(name).Type(&spec.IntegerType{Name: "int", Size: 32}).ForeignKey(true).Index(true).Build()
+	}
+	foreignKey := func(t *spec.Table, field string, rt *spec.Table) *spec.ForeignKey {
+		return &spec.ForeignKey{
+			Name:      t.Name + "_" + field + "_fkey",
+			Fields:    []*spec.Field{t.GetField(field)},
+			RefTable:  rt,
+			RefFields: []*spec.Field{rt.GetField("id")},
+		}
+	}
+
+	user := &spec.Table{Name: "user"}
+	user.AddFields(id())
+
+	profile := &spec.Table{Name: "profile"}
+	profileUserID := fk("user_id")
+	profileUserID.Unique = true
+	profile.AddFields(id(), profileUserID)
+	profile.ForeignKeys = []*spec.ForeignKey{foreignKey(profile, "user_id", user)}
+
+	post := &spec.Table{Name: "post"}
+	post.AddFields(id(), fk("user_id"), fk("author_id"))
+	post.ForeignKeys = []*spec.ForeignKey{foreignKey(post, "user_id", user), foreignKey(post, "author_id", user)}
+	post.ForeignKeys[0].OnDelete, post.ForeignKeys[0].OnUpdate = spec.Cascade, spec.NoAction
+
+	tag := &spec.Table{Name: "tag"}
+	tag.AddFields(id())
+
+	postTag := &spec.Table{Name: "post_tag"}
+	postTag.AddFields(
+		spec.Builder("post_id").Type(&spec.IntegerType{Name: "int", Size: 32}).PrimaryKey(true).ForeignKey(true).Build(),
+		spec.Builder("tag_id").Type(&spec.IntegerType{Name: "int", Size: 32}).PrimaryKey(true).ForeignKey(true).Build(),
+	)
+	postTag.ForeignKeys = []*spec.ForeignKey{foreignKey(postTag, "post_id", post), foreignKey(postTag, "tag_id", tag)}
+
+	s := &spec.Schema{Name: "test"}
+	s.AddTables(user, profile, post, tag, postTag)
+	return s
+}
+
+func TestInferRelations(t *testing.T) {
+	s, err := mergeSchema(inferSchema(), &Config{InferRelations: true})
+	r := require.New(t)
+	r.NoError(err)
+
+	user, profile, post, tag, postTag := s.Table("user"), s.Table("profile"), s.Table("post"), s.Table("tag"), s.Table("post_tag")
+
+	tests := []struct {
+		table    *spec.Table
+		field    string
+		expected *spec.Relation
+	}{
+		{
+			table: profile, field: "user",
+			expected: &spec.Relation{Type: spec.RelTypeBelongsTo, Field: profile.GetField("user_id"), RefTable: user, RefField: user.GetField("id")},
+		},
+		{
+			table: user, field: "profile",
+			expected: &spec.Relation{Type: spec.RelTypeHasOne, Field: user.GetField("id"), RefTable: profile, RefField: profile.GetField("user_id")},
+		},
+		{
+			table: post, field: "user",
+			expected: &spec.Relation{Type: spec.RelTypeBelongsTo, Field: post.GetField("user_id"), RefTable: user, RefField: user.GetField("id"), OnDelete: spec.Cascade, OnUpdate: spec.NoAction},
+		},
+		{
+			table: post, field: "author",
+			expected: &spec.Relation{Type: spec.RelTypeBelongsTo, Field: post.GetField("author_id"), RefTable: user, RefField: user.GetField("id")},
+		},
+		{
+			table: user, field: "posts",
+			expected: &spec.Relation{Type: spec.RelTypeHasMany, Field: user.GetField("id"), RefTable: post, RefField: post.GetField("user_id"), OnDelete: spec.Cascade, OnUpdate: spec.NoAction},
+		},
+		{
+			table: user, field: "author_posts",
+			expected: &spec.Relation{Type: spec.RelTypeHasMany, Field: user.GetField("id"), RefTable: post, RefField: post.GetField("author_id")},
+		},
+		{
+			table: post, field: "tags",
+			expected: &spec.Relation{
+				Type: spec.RelTypeManyToMany, Field: post.GetField("id"), RefTable: tag, RefField: tag.GetField("id"),
+				JoinTable: &spec.JoinTable{Name: "post_tag", JoinField: postTag.GetField("post_id"), JoinRefField: postTag.GetField("tag_id")},
+			},
+		},
+		{
+			table: tag, field: "posts",
+			expected: &spec.Relation{
+				Type: spec.RelTypeManyToMany, Field: tag.GetField("id"), RefTable: post, RefField: post.GetField("id"),
+				JoinTable: &spec.JoinTable{Name: "post_tag", JoinField: postTag.GetField("tag_id"), JoinRefField: postTag.GetField("post_id")},
+				Inverse:   true,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.table.Name+"."+test.field, func(t *testing.T) {
+			f := test.table.GetField(test.field)
+			require.NotNil(t, f)
+			require.Equal(t, test.expected, f.Rel)
+		})
+	}
+
+	r.True(postTag.IsJoinTable)
+	r.Equal(post.GetField("tags").Rel.JoinTable, postTag.JoinTable)
+	r.Len(postTag.Fields(), 2)
+	r.Equal([]*spec.Table{postTag}, s.JoinTables())
+}
+
+func TestInferRelationsConfigWins(t *testing.T) {
+	cfg := &Config{
+		InferRelations: true,
+		Tables: []*Table{
+			{
+				Name: "post",
+				Fields: []*Field{
+					{Name: "owner", Relation: &Relation{Type: "BelongsTo", Field: "user_id", RefTable: "user"}},
+					{Name: "author", Remote: true},
+				},
+			},
+		},
+	}
+
+	s, err := mergeSchema(inferSchema(), cfg)
+	r := require.New(t)
+	r.NoError(err)
+
+	post := s.Table("post")
+	// 已配置的关联不再推断
+	r.Nil(post.GetField("user"))
+	r.Equal(spec.RelTypeBelongsTo, post.GetField("owner").Rel.Type)
+	// 同名字段已存在，不覆盖
+	r.Nil(post.GetField("author").Rel)
+	// 反向关联仍然推断
+	r.Equal(spec.RelTypeHasMany, s.Table("user").GetField("posts").Rel.Type)
+
+	s, err = mergeSchema(inferSchema(), &Config{})
+	r.NoError(err)
+	r.Nil(s.Table("post").GetField("user"))
+	r.Empty(s.JoinTables())
+}
+
+func TestInferRelationsSkipTable(t *testing.T) {
+	s, err := mergeSchema(inferSchema(), &Config{
+		InferRelations: true,
+		Tables:         []*Table{{Name: "tag", Skip: true}},
+	})
+	r := require.New(t)
+	r.NoError(err)
+
+	// 引用已忽略表的外键不参与推断，post_tag 不再视为关联表
+	r.Nil(s.Table("post").GetField("tags"))
+	r.False(s.Table("post_tag").IsJoinTable)
+	r.Equal(spec.RelTypeBelongsTo, s.Table("post_tag").GetField("post").Rel.Type)
+	r.Equal(spec.RelTypeHasMany, s.Table("post").GetField("post_tags").Rel.Type)
+}
+
+func TestRelationReferenceOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		table    string
+		rel      *Relation
+		onDelete spec.ReferenceOption
+		onUpdate spec.ReferenceOption
+		wantErr  bool
+	}{
+		{name: "belongs to", table: "post", rel: &Relation{Type: "BelongsTo", RefTable: "user"}, onDelete: spec.Cascade, onUpdate: spec.NoAction},
+		{name: "has many", table: "user", rel: &Relation{Type: "HasMany", RefTable: "post"}, onDelete: spec.Cascade, onUpdate: spec.NoAction},
+		{name: "override", table: "post", rel: &Relation{Type: "BelongsTo", RefTable: "user", OnDelete: "set null"}, onDelete: spec.SetNull, onUpdate: spec.NoAction},
+		{name: "no foreign key", table: "post", rel: &Relation{Type: "BelongsTo", RefTable: "user", Field: "id"}},
+		{name: "unknown option", table: "post", rel: &Relation{Type: "BelongsTo", RefTable: "user", OnUpdate: "drop"}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			table := inferSchema().Table(test.table)
+			f := spec.Builder("rel").Type(&spec.ObjectType{Name: "rel"}).Build()
+			table.AddFields(f)
+
+			f, err := mergeRelation(f, test.rel)
+			require.Equal(t, test.wantErr, err != nil, err)
+			if test.wantErr {
+				return
+			}
+			require.Equal(t, test.onDelete, f.Rel.OnDelete)
+			require.Equal(t, test.onUpdate, f.Rel.OnUpdate)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package gen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+)
+
+// ManifestPath is the path of the manifest, 相对于 GenRoot
+const ManifestPath = ".cre/manifest.json"
+
+// Manifest lists the files written by the last generation.
+type Manifest struct {
+	Files []*ManifestFile `json:"files"`
+}
+
+type ManifestFile struct {
+	Path     string `json:"path"` // 相对于 GenRoot
+	Template string `json:"template"`
+	Table    string `json:"table,omitempty"` // multi 模式下生成文件的表
+	Hash     string `json:"hash,omitempty"`  // 写入内容的 sha256，按写入策略跳过且从未写入的文件为空
+
+	// Fingerprint 生成该文件的输入的指纹，与本次相同且文件未被修改时跳过生成，见 Config.Force
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// readManifest reads the manifest in the sink, 不存在时返回空的 manifest
+func readManifest(sink Sink) (*Manifest, error) {
+	m := &Manifest{}
+	data, err := sink.ReadFile(ManifestPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	return m, nil
+}
+
+// sinkFile returns the manifest file, 与生成的文件一起写入
+func (m *Manifest) sinkFile() (*SinkFile, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return &SinkFile{Path: ManifestPath, Content: append(data, '\n')}, nil
+}
+
+// index returns the files by path.
+func (m *Manifest) index() map[string]*ManifestFile {
+	files := make(map[string]*ManifestFile, len(m.Files))
+	for _, f := range m.Files {
+		files[f.Path] = f
+	}
+	return files
+}
+
+// compare compares the prepared files with the last manifest,
+// 记录上次生成后被手工修改的文件和当前 schema 不再生成的文件
+func (a assets) compare(sink Sink, last *Manifest, report *Report) error {
+	files := last.index()
+	generated := make(map[string]bool, len(a.files))
+	for _, f := range a.files {
+		generated[f.path] = true
+
+		// 按写入策略跳过的文件本就由手工维护
+		if f.skipped || !f.exists {
+			continue
+		}
+		if mf := files[f.path]; mf != nil && mf.Hash != "" && mf.Hash != checksum(f.existing) {
+			report.Edited = append(report.Edited, f.path)
+		}
+	}
+
+	for _, mf := range last.Files {
+		if generated[mf.Path] {
+			continue
+		}
+		content, err := sink.ReadFile(mf.Path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		report.Stale = append(report.Stale, mf.Path)
+		if mf.Hash != "" && mf.Hash != checksum(content) {
+			report.Edited = append(report.Edited, mf.Path)
+		}
+	}
+	return nil
+}
+
+// prunable returns the stale files which are not edited by hand.
+func prunable(report *Report) []string {
+	edited := make(map[string]bool, len(report.Edited))
+	for _, path := range report.Edited {
+		edited[path] = true
+	}
+	var paths []string
+	for _, path := range report.Stale {
+		if !edited[path] {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// manifest returns the manifest of the written files, 未删除(不在 report.Pruned 中)的旧文件仍保留在 manifest 中
+func (a assets) manifest(last *Manifest, report *Report) *Manifest {
+	files := last.index()
+	m := &Manifest{}
+	for _, f := range a.files {
+		mf := &ManifestFile{Path: f.path, Template: f.template, Table: f.table}
+		if !f.skipped {
+			mf.Hash = checksum(f.content)
+			mf.Fingerprint = f.fingerprint
+		} else if prev := files[f.path]; prev != nil {
+			mf.Hash = prev.Hash
+			mf.Fingerprint = prev.Fingerprint
+		}
+		m.Files = append(m.Files, mf)
+		delete(files, f.path)
+	}
+
+	pruned := make(map[string]bool, len(report.Pruned))
+	for _, path := range report.Pruned {
+		pruned[path] = true
+	}
+	for _, path := range report.Stale {
+		if pruned[path] {
+			continue
+		}
+		if mf := files[path]; mf != nil {
+			m.Files = append(m.Files, mf)
+		}
+	}
+
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].Path < m.Files[j].Path })
+	return m
+}
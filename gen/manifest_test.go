@@ -0,0 +1,72 @@
+package gen
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifest(t *testing.T) {
+	r := require.New(t)
+
+	sink := NewMemSink()
+	run := func(prune bool, files ...file) *Report {
+		last, err := readManifest(sink)
+		r.NoError(err)
+		a := assets{files: files}
+		report, err := a.prepare(context.Background(), sink)
+		r.NoError(err)
+		r.NoError(a.compare(sink, last, report))
+		if prune {
+			report.Pruned = prunable(report)
+		}
+		mf, err := a.manifest(last, report).sinkFile()
+		r.NoError(err)
+		r.NoError(sink.WriteFiles(append(a.sinkFiles(), mf)))
+		for _, path := range report.Pruned {
+			r.NoError(sink.Remove(path))
+		}
+		return report
+	}
+	gen := func(name, table string) file {
+		return file{path: name, content: []byte(table + "\n"), template: "table.tmpl", table: table, policy: WriteAlways}
+	}
+
+	report := run(false, gen("user.txt", "user"), gen("post.txt", "post"), gen("tag.txt", "tag"))
+	r.Empty(report.Edited)
+	r.Empty(report.Stale)
+
+	m, err := readManifest(sink)
+	r.NoError(err)
+	r.Equal([]*ManifestFile{
+		{Path: "post.txt", Template: "table.tmpl", Table: "post", Hash: checksum([]byte("post\n"))},
+		{Path: "tag.txt", Template: "table.tmpl", Table: "tag", Hash: checksum([]byte("tag\n"))},
+		{Path: "user.txt", Template: "table.tmpl", Table: "user", Hash: checksum([]byte("user\n"))},
+	}, m.Files)
+
+	// post 表被删除，tag 表被删除且文件被手工修改
+	r.NoError(sink.WriteFiles([]*SinkFile{
+		{Path: "user.txt", Content: []byte("user edited\n")},
+		{Path: "tag.txt", Content: []byte("tag edited\n")},
+	}))
+
+	report = run(false, gen("user.txt", "user"))
+	r.Equal([]string{"user.txt", "tag.txt"}, report.Edited)
+	r.Equal([]string{"post.txt", "tag.txt"}, report.Stale)
+	r.Empty(report.Pruned)
+	r.Contains(sink.FS, "post.txt")
+
+	report = run(true, gen("user.txt", "user"))
+	r.Equal([]string{"tag.txt"}, report.Edited)
+	r.Equal([]string{"post.txt"}, report.Pruned)
+	r.NotContains(sink.FS, "post.txt")
+	r.Contains(sink.FS, "tag.txt")
+
+	// 手工修改的旧文件保留在 manifest 中
+	m, err = readManifest(sink)
+	r.NoError(err)
+	r.Len(m.Files, 2)
+	r.Equal("tag.txt", m.Files[0].Path)
+	r.Equal("user.txt", m.Files[1].Path)
+}
@@ -2,6 +2,7 @@ package gen
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/ychengcloud/cre/spec"
 )
@@ -36,6 +37,9 @@ func mergeJoinTable(f *spec.Field, joinTableInCfg *JoinTable) (*spec.Field, erro
 		joinTableInCfg.Name = f.Table.Name + "_" + f.Rel.RefTable.Name
 	}
 	if joinTableInCfg.Field == "" {
+		if f.Table.ID == nil {
+			return nil, fmt.Errorf("join field of table %s is required for composite primary key", f.Table.Name)
+		}
 		joinTableInCfg.Field = f.Table.Name + "_" + f.Table.ID.Name
 
 	}
@@ -181,9 +185,58 @@ func mergeRelation(f *spec.Field, rel *Relation) (*spec.Field, error) {
 	if rel.Inverse {
 		f.Rel.Inverse = rel.Inverse
 	}
+
+	referenceOptions(f.Rel)
+	if f.Rel.OnDelete, err = mergeReferenceOption(f.Rel.OnDelete, rel.OnDelete); err != nil {
+		return nil, err
+	}
+	if f.Rel.OnUpdate, err = mergeReferenceOption(f.Rel.OnUpdate, rel.OnUpdate); err != nil {
+		return nil, err
+	}
 	return f, nil
 }
 
+// referenceOptions sets the referential actions of the relation from the foreign key.
+//
+//	BelongsTo         => 外键在本表
+//	HasOne / HasMany  => 外键在引用表
+//	ManyToMany        => 外键在关联表，引用本表
+func referenceOptions(rel *spec.Relation) {
+	var field, refField *spec.Field
+	switch rel.Type {
+	case spec.RelTypeBelongsTo:
+		field, refField = rel.Field, rel.RefField
+	case spec.RelTypeHasOne, spec.RelTypeHasMany:
+		field, refField = rel.RefField, rel.Field
+	case spec.RelTypeManyToMany:
+		if rel.JoinTable != nil {
+			field, refField = rel.JoinTable.JoinField, rel.Field
+		}
+	}
+	if field == nil || refField == nil || field.Table == nil {
+		return
+	}
+
+	for _, fk := range field.Table.ForeignKeys {
+		if len(fk.Fields) == 1 && len(fk.RefFields) == 1 && fk.Fields[0] == field && fk.RefFields[0] == refField {
+			rel.OnDelete, rel.OnUpdate = fk.OnDelete, fk.OnUpdate
+			return
+		}
+	}
+}
+
+func mergeReferenceOption(option spec.ReferenceOption, cfg string) (spec.ReferenceOption, error) {
+	if cfg == "" {
+		return option, nil
+	}
+	o := spec.ReferenceOption(strings.ToUpper(strings.TrimSpace(cfg)))
+	switch o {
+	case spec.NoAction, spec.Restrict, spec.Cascade, spec.SetNull, spec.SetDefault:
+		return o, nil
+	}
+	return "", fmt.Errorf("unknown reference option: %s", cfg)
+}
+
 func mergeType(t Type) spec.Type {
 	if t == "" {
 		return nil
@@ -272,10 +325,7 @@ func mergeField(f *spec.Field, fc *Field) (*spec.Field, error) {
 		}
 	}
 
-	for k, v := range fc.Attrs {
-		attr := NewAttr(k, v)
-		f.Attrs = append(f.Attrs, attr)
-	}
+	f.Attrs = append(f.Attrs, newAttrs(fc.Attrs)...)
 
 	var err error
 
@@ -324,10 +374,7 @@ func mergeTable(t *spec.Table, tc *Table) (*spec.Table, error) {
 		}
 	}
 
-	for k, v := range tc.Attrs {
-		attr := NewAttr(k, v)
-		t.Attrs = append(t.Attrs, attr)
-	}
+	t.Attrs = append(t.Attrs, newAttrs(tc.Attrs)...)
 
 	return t, nil
 }
@@ -336,10 +383,7 @@ func mergeSchema(s *spec.Schema, cfg *Config) (*spec.Schema, error) {
 		return nil, fmt.Errorf("schema is nil")
 	}
 
-	for k, v := range cfg.Attrs {
-		attr := NewAttr(k, v)
-		s.Attrs = append(s.Attrs, attr)
-	}
+	s.Attrs = append(s.Attrs, newAttrs(cfg.Attrs)...)
 
 	for _, tc := range cfg.Tables {
 		if tc.Skip {
@@ -355,5 +399,10 @@ func mergeSchema(s *spec.Schema, cfg *Config) (*spec.Schema, error) {
 			return nil, err
 		}
 	}
+
+	// 在配置合并之后推断，手写配置的关联优先
+	if cfg.InferRelations {
+		inferRelations(s)
+	}
 	return s, nil
 }
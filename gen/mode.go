@@ -0,0 +1,290 @@
+package gen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ychengcloud/cre/spec"
+)
+
+// enumData is the data of the enum mode, 每个枚举类型渲染一次
+// Postgres 等命名的枚举类型按 namespace 和名称合并，MySQL 等在列上定义的枚举以 表名_字段名 命名
+// schema 包含多个 namespace 时名称前加上 namespace，如 billing_status、billing_order_status，避免不同 schema 中的同名枚举冲突
+//
+//	format: "{{ .Name }}.go"
+type enumData struct {
+	*spec.EnumType
+
+	Name      string        // 枚举名称，覆盖 EnumType.Name
+	Namespace string        // 使用该枚举的表所在的 namespace
+	Fields    []*spec.Field // 使用该枚举的字段，Field.Table 为字段所在的表
+
+	Schema    *spec.Schema
+	Generator *Generator
+	Project   string
+	Package   string
+}
+
+// relationData is the data of the relation mode, 每条关联渲染一次，两端的关联字段只渲染一次
+//
+//	format: "{{ .Table.Name }}_{{ .Field.Name }}.go"
+type relationData struct {
+	Table    *spec.Table // 先出现的一端
+	Field    *spec.Field // Table 中的关联字段，关联定义为 Field.Rel
+	RefTable *spec.Table // 另一端
+	Inverse  *spec.Field // RefTable 中指向 Table 的关联字段，没有时为 nil
+
+	Schema    *spec.Schema
+	Generator *Generator
+	Project   string
+	Package   string
+}
+
+// joinData is the data of the join mode, 每个关联表(IsJoinTable)渲染一次
+//
+//	format: "{{ .Table.Name }}.go"
+type joinData struct {
+	*spec.Table
+
+	Left  *spec.Table // 关联表的 JoinField 引用的表，没有 ManyToMany 关联时为 nil
+	Right *spec.Table // 关联表的 JoinRefField 引用的表
+
+	Generator *Generator
+	Project   string
+	Package   string
+}
+
+// groupData is the data of the group mode, 按表的 attr 或名称前缀分组，每组渲染一次
+// Tables 为分组中的表，single 模式的模板可直接用于分组
+//
+//	group: "attr:module"  => attrs 中 module 的值，没有该 attr 的表不参与
+//	group: "prefix"       => 表名中第一个 _ 之前的部分，如 auth_user => auth
+//	group: "prefix:."     => 以 . 分隔的前缀
+//	format: "{{ .Group }}.go"
+type groupData struct {
+	*spec.Schema
+
+	Group  string
+	Tables []*spec.Table // 按 Schema.Tables 的顺序
+
+	Generator *Generator
+	Project   string
+	Package   string
+}
+
+// 每个枚举类型生成一个文件
+func (g *Generator) generateEnum(tplCfg *Template) error {
+	var enums []*enumData
+	named := make(map[string]*enumData)
+	qualified := len(g.schema.Namespaces()) > 1
+	enumName := func(namespace, name string) string {
+		if qualified && namespace != "" {
+			return namespace + "_" + name
+		}
+		return name
+	}
+
+	for _, table := range g.schema.Tables() {
+		for _, field := range table.Fields() {
+			et, ok := field.Type.(*spec.EnumType)
+			if !ok {
+				continue
+			}
+			name := et.Name
+			// 未命名的枚举类型，如 MySQL 的 enum('a','b')
+			if name == "" || strings.EqualFold(name, "enum") {
+				enums = append(enums, g.enumData(enumName(table.Namespace, table.Name+"_"+field.Name), et, field))
+				continue
+			}
+			key := spec.QualifiedName(table.Namespace, name)
+			if e, ok := named[key]; ok {
+				e.Fields = append(e.Fields, field)
+				continue
+			}
+			named[key] = g.enumData(enumName(table.Namespace, name), et, field)
+			enums = append(enums, named[key])
+		}
+	}
+
+	for _, e := range enums {
+		g.queue(tplCfg, e)
+	}
+	return nil
+}
+
+func (g *Generator) enumData(name string, et *spec.EnumType, field *spec.Field) *enumData {
+	return &enumData{
+		EnumType:  et,
+		Name:      name,
+		Namespace: field.Table.Namespace,
+		Fields:    []*spec.Field{field},
+		Schema:    g.schema,
+		Generator: g,
+		Project:   g.Cfg.Project,
+		Package:   g.Cfg.Package,
+	}
+}
+
+// 每条关联生成一个文件
+func (g *Generator) generateRelation(tplCfg *Template) error {
+	done := make(map[*spec.Field]bool)
+
+	for _, table := range g.schema.Tables() {
+		for _, field := range table.SortedFields() {
+			if field.Rel == nil || field.Rel.RefTable == nil || done[field] {
+				continue
+			}
+			inverse := inverseField(table, field)
+			done[field] = true
+			if inverse != nil {
+				done[inverse] = true
+			}
+			g.queue(tplCfg, &relationData{
+				Table:     table,
+				Field:     field,
+				RefTable:  field.Rel.RefTable,
+				Inverse:   inverse,
+				Schema:    g.schema,
+				Generator: g,
+				Project:   g.Cfg.Project,
+				Package:   g.Cfg.Package,
+			})
+		}
+	}
+	return nil
+}
+
+// inverseField returns the field of the other side of the relation,
+// 即引用表中字段相同、方向相反的关联字段，ManyToMany 关联还需使用相同的关联表
+func inverseField(table *spec.Table, field *spec.Field) *spec.Field {
+	rel := field.Rel
+	for _, f := range rel.RefTable.SortedFields() {
+		if f == field || f.Rel == nil {
+			continue
+		}
+		r := f.Rel
+		if r.RefTable != table || r.Field != rel.RefField || r.RefField != rel.Field {
+			continue
+		}
+		if (r.JoinTable == nil) != (rel.JoinTable == nil) {
+			continue
+		}
+		if r.JoinTable != nil && r.JoinTable.Name != rel.JoinTable.Name {
+			continue
+		}
+		return f
+	}
+	return nil
+}
+
+// 每个关联表生成一个文件
+func (g *Generator) generateJoin(tplCfg *Template) error {
+	for _, table := range g.schema.Tables() {
+		if !table.IsJoinTable {
+			continue
+		}
+		jd := &joinData{
+			Table:     table,
+			Generator: g,
+			Project:   g.Cfg.Project,
+			Package:   g.Cfg.Package,
+		}
+		if jt := table.JoinTable; jt != nil && jt.JoinField != nil && jt.JoinRefField != nil {
+			jd.Left, jd.Right = joinRefTable(table, jt.JoinField), joinRefTable(table, jt.JoinRefField)
+		}
+		g.queue(tplCfg, jd)
+	}
+	return nil
+}
+
+// joinRefTable returns the table referenced by the field of the join table.
+func joinRefTable(table *spec.Table, field *spec.Field) *spec.Table {
+	for _, fk := range table.ForeignKeys {
+		if len(fk.Fields) == 1 && fk.Fields[0] == field {
+			return fk.RefTable
+		}
+	}
+	// 没有外键时根据 ManyToMany 关联查找
+	for _, t := range table.Schema.Tables() {
+		for _, f := range t.Fields() {
+			if !f.RelManyToMany() || f.Rel.JoinTable == nil || f.Rel.JoinTable.Name != table.Name {
+				continue
+			}
+			if f.Rel.JoinTable.JoinField == field {
+				return t
+			}
+			if f.Rel.JoinTable.JoinRefField == field {
+				return f.Rel.RefTable
+			}
+		}
+	}
+	return nil
+}
+
+// 按表的 attr 或名称前缀分组，每组生成一个文件
+func (g *Generator) generateGroup(tplCfg *Template) error {
+	groupOf, err := tplCfg.groupBy()
+	if err != nil {
+		return err
+	}
+
+	var groups []*groupData
+	index := make(map[string]*groupData)
+	for _, table := range g.schema.Tables() {
+		// 与 multi 模式相同，复合主键的关联表不参与
+		if table.IsJoinTable && table.ID == nil {
+			continue
+		}
+		name, ok := groupOf(table)
+		if !ok {
+			continue
+		}
+		gd, ok := index[name]
+		if !ok {
+			gd = &groupData{
+				Schema:    g.schema,
+				Group:     name,
+				Generator: g,
+				Project:   g.Cfg.Project,
+				Package:   g.Cfg.Package,
+			}
+			index[name] = gd
+			groups = append(groups, gd)
+		}
+		gd.Tables = append(gd.Tables, table)
+	}
+
+	for _, gd := range groups {
+		g.queue(tplCfg, gd)
+	}
+	return nil
+}
+
+// groupBy returns the group of the table by Template.Group, 表不属于任何分组时 ok 为 false
+func (t *Template) groupBy() (func(*spec.Table) (string, bool), error) {
+	kind, arg, _ := strings.Cut(t.Group, ":")
+	switch kind {
+	case "attr":
+		if arg == "" {
+			return nil, fmt.Errorf("template %s: group attr is empty", t.Path)
+		}
+		return func(table *spec.Table) (string, bool) {
+			for _, attr := range table.Attrs {
+				if attr.Name() == arg && attr.Value() != nil {
+					return fmt.Sprint(attr.Value()), true
+				}
+			}
+			return "", false
+		}, nil
+	case "prefix":
+		if arg == "" {
+			arg = "_"
+		}
+		return func(table *spec.Table) (string, bool) {
+			prefix, _, _ := strings.Cut(table.Name, arg)
+			return prefix, true
+		}, nil
+	default:
+		return nil, fmt.Errorf("template %s: unknown group %q", t.Path, t.Group)
+	}
+}
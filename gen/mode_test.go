@@ -0,0 +1,147 @@
+package gen
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ychengcloud/cre/spec"
+)
+
+func TestModes(t *testing.T) {
+	r := require.New(t)
+
+	id := func() *spec.Field {
+		return spec.Builder("id").Type(&spec.IntegerType{Name: "int", Size: 64}).PrimaryKey(true).Build()
+	}
+	newTable := func(name string, module string, fields ...*spec.Field) *spec.Table {
+		table := &spec.Table{Name: name}
+		table.AddFields(id())
+		table.AddFields(fields...)
+		table.ID = table.GetField("id")
+		if module != "" {
+			table.Attrs = append(table.Attrs, NewAttr("module", module))
+		}
+		return table
+	}
+	mood := &spec.EnumType{Name: "mood", Values: []string{"happy", "sad"}}
+	user := newTable("user", "auth", spec.Builder("mood").Type(mood).Build())
+	post := newTable("post", "blog",
+		spec.Builder("user_id").Type(&spec.IntegerType{Name: "int", Size: 64}).Build(),
+		spec.Builder("mood").Type(mood).Build(),
+		spec.Builder("status").Type(&spec.EnumType{Name: "enum", Values: []string{"draft", "published"}}).Build(),
+	)
+	tag := newTable("tag", "blog")
+	postLike := newTable("post_like", "")
+	postTag := &spec.Table{Name: "post_tag", IsJoinTable: true}
+	postTag.AddFields(
+		spec.Builder("post_id").Type(&spec.IntegerType{Name: "int", Size: 64}).PrimaryKey(true).Build(),
+		spec.Builder("tag_id").Type(&spec.IntegerType{Name: "int", Size: 64}).PrimaryKey(true).Build(),
+	)
+	postTag.JoinTable = &spec.JoinTable{Name: "post_tag", JoinField: postTag.GetField("post_id"), JoinRefField: postTag.GetField("tag_id")}
+
+	object := func(name string) *spec.ObjectType { return &spec.ObjectType{Name: name} }
+	post.AddFields(
+		spec.Builder("author").Type(object("author")).Rel(&spec.Relation{
+			Type: spec.RelTypeBelongsTo, Field: post.GetField("user_id"), RefTable: user, RefField: user.ID,
+		}).Build(),
+		spec.Builder("tags").Type(object("tags")).Rel(&spec.Relation{
+			Type: spec.RelTypeManyToMany, Field: post.ID, RefTable: tag, RefField: tag.ID, JoinTable: postTag.JoinTable,
+		}).Build(),
+	)
+	user.AddFields(spec.Builder("posts").Type(object("posts")).Rel(&spec.Relation{
+		Type: spec.RelTypeHasMany, Field: user.ID, RefTable: post, RefField: post.GetField("user_id"),
+	}).Build())
+	tag.AddFields(spec.Builder("posts").Type(object("posts")).Rel(&spec.Relation{
+		Type: spec.RelTypeManyToMany, Field: tag.ID, RefTable: post, RefField: post.ID, Inverse: true,
+		JoinTable: &spec.JoinTable{Name: "post_tag", JoinField: postTag.GetField("tag_id"), JoinRefField: postTag.GetField("post_id")},
+	}).Build())
+
+	schema := &spec.Schema{Name: "main"}
+	schema.AddTables(user, post, tag, postLike, postTag)
+
+	root := fstest.MapFS{
+		"enum.tmpl":     {Data: []byte("{{ .Name }}:{{ range .Values }} {{ . }}{{ end }} ({{ range .Fields }}{{ .Table.Name }}.{{ .Name }} {{ end }})\n")},
+		"relation.tmpl": {Data: []byte("{{ .Table.Name }}.{{ .Field.Name }} -> {{ .RefTable.Name }}{{ with .Inverse }}.{{ .Name }}{{ end }}\n")},
+		"join.tmpl":     {Data: []byte("{{ .Name }}: {{ .Left.Name }} {{ .Right.Name }}\n")},
+		"group.tmpl":    {Data: []byte("{{ .Group }}:{{ range .Tables }} {{ .Name }}{{ end }}\n")},
+		"prefix.tmpl":   {Data: []byte("{{ .Group }}:{{ range .Tables }} {{ .Name }}{{ end }}\n")},
+	}
+	sink := NewMemSink()
+	generate := func() *Generator {
+		g, _ := newMemGenerator(t, &Config{
+			Templates: []*Template{
+				{Path: "enum.tmpl", GenPath: "enum", Format: "{{ .Name }}.txt", Mode: TplModeEnum},
+				{Path: "relation.tmpl", GenPath: "relation", Format: "{{ .Table.Name }}_{{ .Field.Name }}.txt", Mode: TplModeRelation},
+				{Path: "join.tmpl", GenPath: "join", Format: "{{ .Table.Name }}.txt", Mode: TplModeJoin},
+				{Path: "group.tmpl", GenPath: "module", Format: "{{ .Group }}.txt", Mode: TplModeGroup, Group: "attr:module"},
+				{Path: "prefix.tmpl", GenPath: "prefix", Format: "{{ .Group }}.txt", Mode: TplModeGroup, Group: "prefix"},
+			},
+		}, schema, root)
+		// 使用同一个 sink 验证增量生成
+		g.Sink = sink
+		r.NoError(g.Generate(context.Background()))
+		return g
+	}
+	generate()
+
+	files := memFiles(sink)
+	r.Equal(map[string]string{
+		"enum/mood.txt":           "mood: happy sad (user.mood post.mood )\n",
+		"enum/post_status.txt":    "post_status: draft published (post.status )\n",
+		"relation/user_posts.txt": "user.posts -> post.author\n",
+		"relation/post_tags.txt":  "post.tags -> tag.posts\n",
+		"join/post_tag.txt":       "post_tag: post tag\n",
+		"module/auth.txt":         "auth: user\n",
+		"module/blog.txt":         "blog: post tag\n",
+		"prefix/user.txt":         "user: user\n",
+		"prefix/post.txt":         "post: post post_like\n",
+		"prefix/tag.txt":          "tag: tag\n",
+	}, files)
+
+	// 新的模式同样支持增量生成
+	r.Len(generate().Report().Cached, len(files))
+
+	m, err := readManifest(sink)
+	r.NoError(err)
+	for _, mf := range m.Files {
+		if mf.Path == "join/post_tag.txt" {
+			r.Equal("post_tag", mf.Table)
+		}
+	}
+
+	_, err = (&Template{Path: "group.tmpl", Group: "suffix"}).groupBy()
+	r.EqualError(err, `template group.tmpl: unknown group "suffix"`)
+}
+
+func TestEnumNamespaces(t *testing.T) {
+	r := require.New(t)
+
+	schema := &spec.Schema{Name: "main"}
+	for _, ns := range []string{"billing", "shop"} {
+		order := &spec.Table{Name: "order", Namespace: ns}
+		order.AddFields(
+			spec.Builder("id").Type(&spec.IntegerType{Name: "int", Size: 64}).PrimaryKey(true).Build(),
+			spec.Builder("status").Type(&spec.EnumType{Name: "status", Values: []string{ns + "_a", ns + "_b"}}).Build(),
+			spec.Builder("kind").Type(&spec.EnumType{Name: "enum", Values: []string{ns}}).Build(),
+		)
+		order.ID = order.GetField("id")
+		schema.AddTables(order)
+	}
+
+	root := fstest.MapFS{
+		"enum.tmpl": {Data: []byte("{{ .Namespace }}:{{ range .Values }} {{ . }}{{ end }}\n")},
+	}
+	files := generateMem(t, &Config{
+		Templates: []*Template{{Path: "enum.tmpl", GenPath: "enum", Format: "{{ .Name }}.txt", Mode: TplModeEnum}},
+	}, schema, root)
+	// 不同 schema 中的同名枚举分别生成
+	r.Equal(map[string]string{
+		"enum/billing_status.txt":     "billing: billing_a billing_b\n",
+		"enum/shop_status.txt":        "shop: shop_a shop_b\n",
+		"enum/billing_order_kind.txt": "billing: billing\n",
+		"enum/shop_order_kind.txt":    "shop: shop\n",
+	}, files)
+}
@@ -0,0 +1,57 @@
+package gen
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// parallel calls fn for 0..n-1 with at most jobs goroutines, jobs <= 0 时为 CPU 数
+// 出错或 ctx 取消后不再开始新的任务；返回序号最小的错误，与顺序执行时的错误相同
+func parallel(ctx context.Context, jobs, n int, fn func(i int) error) error {
+	if jobs <= 0 {
+		jobs = runtime.GOMAXPROCS(0)
+	}
+	if jobs > n {
+		jobs = n
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, n)
+		next = make(chan int)
+	)
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if errs[i] = fn(i); errs[i] != nil {
+					cancel()
+				}
+			}
+		}()
+	}
+
+	// 按顺序分发，出错的任务之前的任务均已开始执行
+	canceled := false
+	for i := 0; i < n && !canceled; i++ {
+		select {
+		case next <- i:
+		case <-ctx.Done():
+			canceled = true
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,65 @@
+package gen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParallel(t *testing.T) {
+	r := require.New(t)
+
+	results := make([]int, 100)
+	r.NoError(parallel(context.Background(), 8, len(results), func(i int) error {
+		results[i] = i * i
+		return nil
+	}))
+	for i, v := range results {
+		r.Equal(i*i, v)
+	}
+
+	// 返回序号最小的错误
+	var started int32
+	err := parallel(context.Background(), 8, 100, func(i int) error {
+		atomic.AddInt32(&started, 1)
+		if i >= 10 {
+			return fmt.Errorf("job %d", i)
+		}
+		return nil
+	})
+	r.EqualError(err, "job 10")
+	r.Less(int(atomic.LoadInt32(&started)), 100)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = parallel(ctx, 1, 100, func(i int) error { return nil })
+	r.True(errors.Is(err, context.Canceled))
+}
+
+func TestRenderJobsDeterministic(t *testing.T) {
+	r := require.New(t)
+
+	generate := func(jobs int) *MemSink {
+		g := newTablesGenerator(r, 50)
+		g.Cfg.Jobs = jobs
+		sink := NewMemSink()
+		g.Sink = sink
+		r.NoError(g.Generate(context.Background()))
+		return sink
+	}
+	sequential, concurrent := generate(1), generate(8)
+	r.Len(sequential.FS, 51)
+	for name, f := range sequential.FS {
+		r.Equal(string(f.Data), string(concurrent.FS[name].Data), name)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	g := newTablesGenerator(r, 50)
+	g.Sink = NewMemSink()
+	r.ErrorIs(g.Generate(ctx), context.Canceled)
+}
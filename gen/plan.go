@@ -0,0 +1,109 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// 文件更改类型
+const (
+	ChangeCreated   = "created"
+	ChangeModified  = "modified"
+	ChangeUnchanged = "unchanged"
+	ChangeSkipped   = "skipped" // 按写入策略跳过
+	ChangeDeleted   = "deleted" // 当前 schema 不再生成且未被手工修改的文件，Config.Prune 为 true 时删除
+)
+
+// Plan is the result of a dry run.
+type Plan struct {
+	*Report
+	Changes []*Change
+}
+
+// Change is the change of a file, Old 为已有的内容，New 为生成的内容
+type Change struct {
+	Path string
+	Op   string
+	Old  []byte
+	New  []byte
+}
+
+// Count returns the number of the changes of the op.
+func (p *Plan) Count(op string) int {
+	n := 0
+	for _, c := range p.Changes {
+		if c.Op == op {
+			n++
+		}
+	}
+	return n
+}
+
+// Diff returns the unified diff of the change, 未更改和跳过的文件返回空
+func (c *Change) Diff() (string, error) {
+	diff := difflib.UnifiedDiff{
+		FromFile: "a/" + filepath.ToSlash(c.Path),
+		ToFile:   "b/" + filepath.ToSlash(c.Path),
+		Context:  3,
+	}
+	switch c.Op {
+	case ChangeCreated:
+		diff.FromFile = "/dev/null"
+	case ChangeDeleted:
+		diff.ToFile = "/dev/null"
+	case ChangeModified:
+	default:
+		return "", nil
+	}
+	if isBinary(c.Old) || isBinary(c.New) {
+		return fmt.Sprintf("Binary files %s and %s differ\n", diff.FromFile, diff.ToFile), nil
+	}
+	if len(c.Old) > 0 {
+		diff.A = difflib.SplitLines(string(c.Old))
+	}
+	if len(c.New) > 0 {
+		diff.B = difflib.SplitLines(string(c.New))
+	}
+	return difflib.GetUnifiedDiffString(diff)
+}
+
+// plan compares the prepared files with the files in the sink, prune 为 true 时包含将被删除的文件
+func (a assets) plan(sink Sink, report *Report, prune bool) (*Plan, error) {
+	p := &Plan{Report: report}
+
+	for _, f := range a.files {
+		c := &Change{Path: f.path, Old: f.existing, New: f.content}
+		switch {
+		case f.skipped:
+			c.Op, c.New = ChangeSkipped, f.existing
+		case !f.exists:
+			c.Op = ChangeCreated
+		case bytes.Equal(f.existing, f.content):
+			c.Op = ChangeUnchanged
+		default:
+			c.Op = ChangeModified
+		}
+		p.Changes = append(p.Changes, c)
+	}
+
+	if !prune {
+		return p, nil
+	}
+	for _, path := range prunable(report) {
+		content, err := sink.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		p.Changes = append(p.Changes, &Change{Path: path, Op: ChangeDeleted, Old: content})
+	}
+	return p, nil
+}
+
+// isBinary reports whether the content is not text, 如图片等二进制静态文件
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content, 0) >= 0 || !utf8.Valid(content)
+}
@@ -0,0 +1,171 @@
+package gen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/ychengcloud/cre/spec"
+)
+
+// PluginVersion is the version of the plugin protocol, 不兼容的修改需要递增版本号
+const PluginVersion = 1
+
+// 插件诊断信息的级别
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Plugin is an external generator, 类似 protoc 插件:
+// 从标准输入读取 JSON 格式的 PluginRequest，向标准输出写入 JSON 格式的 PluginResponse，
+// 返回的文件与模板生成的文件一样合并保护区域、格式化和写入
+//
+//	plugins:
+//	  - name: cre-gen-ts
+//	    out: web/
+type Plugin struct {
+	Name  string   `yaml:"name" mapstructure:"name"`   // 可执行文件，不含路径分隔符时在 PATH 中查找
+	Args  []string `yaml:"args" mapstructure:"args"`   // 命令行参数
+	Out   string   `yaml:"out" mapstructure:"out"`     // 生成路径，相对于 GenRoot
+	Write string   `yaml:"write" mapstructure:"write"` // 写入策略，同 Template.Write
+}
+
+// PluginRequest is written to the stdin of the plugin.
+type PluginRequest struct {
+	Version  int            `json:"version"`  // PluginVersion
+	Snapshot *spec.Snapshot `json:"snapshot"` // 合并配置后的 schema
+	Attrs    map[string]any `json:"attrs,omitempty"`
+	Project  string         `json:"project,omitempty"`
+	Package  string         `json:"package,omitempty"`
+}
+
+// PluginResponse is read from the stdout of the plugin.
+type PluginResponse struct {
+	Files       []*PluginFile `json:"files"`
+	Diagnostics []*Diagnostic `json:"diagnostics,omitempty"`
+}
+
+// PluginFile is a file generated by the plugin.
+type PluginFile struct {
+	Path    string      `json:"path"` // 相对于 Plugin.Out 的 / 分隔路径
+	Content string      `json:"content"`
+	Mode    fs.FileMode `json:"mode,omitempty"` // 文件权限，为 0 时使用 0644
+}
+
+// Diagnostic is a message reported by the plugin, 存在 error 级别的诊断信息时生成失败
+type Diagnostic struct {
+	Plugin   string `json:"plugin,omitempty"`
+	Severity string `json:"severity"`
+	Path     string `json:"path,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (d *Diagnostic) String() string {
+	if d.Path != "" {
+		return fmt.Sprintf("%s: %s: %s: %s", d.Plugin, d.Severity, d.Path, d.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", d.Plugin, d.Severity, d.Message)
+}
+
+// runPlugins runs the plugins with at most Config.Jobs processes and adds the files to the assets,
+// 文件按插件的配置顺序添加，返回 warning 级别的诊断信息
+func (g *Generator) runPlugins(ctx context.Context) ([]*Diagnostic, error) {
+	if len(g.Cfg.Plugins) == 0 {
+		return nil, nil
+	}
+
+	snapshot, err := spec.NewSnapshot(g.schema)
+	if err != nil {
+		return nil, err
+	}
+	req, err := json.Marshal(&PluginRequest{
+		Version:  PluginVersion,
+		Snapshot: snapshot,
+		Attrs:    g.Cfg.Attrs,
+		Project:  g.Cfg.Project,
+		Package:  g.Cfg.Package,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("plugin request: %w", err)
+	}
+
+	files := make([][]file, len(g.Cfg.Plugins))
+	diags := make([][]*Diagnostic, len(g.Cfg.Plugins))
+	err = parallel(ctx, g.Cfg.Jobs, len(g.Cfg.Plugins), func(i int) error {
+		var err error
+		files[i], diags[i], err = g.runPlugin(ctx, g.Cfg.Plugins[i], req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []*Diagnostic
+	for i := range g.Cfg.Plugins {
+		g.assets.files = append(g.assets.files, files[i]...)
+		warnings = append(warnings, diags[i]...)
+	}
+	return warnings, nil
+}
+
+// runPlugin runs the plugin with the request, 插件退出码非 0 或返回 error 级别的诊断信息时返回错误
+func (g *Generator) runPlugin(ctx context.Context, p *Plugin, req []byte) ([]file, []*Diagnostic, error) {
+	policy, err := (&Template{Path: "plugin " + p.Name, Write: p.Write}).writePolicy(g.Cfg.DefaultWrite)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.Name, p.Args...)
+	cmd.Stdin = bytes.NewReader(req)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, nil, fmt.Errorf("plugin %s: %w: %s", p.Name, err, msg)
+		}
+		return nil, nil, fmt.Errorf("plugin %s: %w", p.Name, err)
+	}
+
+	resp := &PluginResponse{}
+	if err := json.Unmarshal(stdout.Bytes(), resp); err != nil {
+		return nil, nil, fmt.Errorf("plugin %s: read response: %w", p.Name, err)
+	}
+
+	var errs []string
+	var diags []*Diagnostic
+	for _, d := range resp.Diagnostics {
+		d.Plugin = p.Name
+		if d.Severity == SeverityError {
+			errs = append(errs, d.String())
+			continue
+		}
+		diags = append(diags, d)
+	}
+	if len(errs) > 0 {
+		return nil, nil, fmt.Errorf("plugin %s failed:\n%s", p.Name, strings.Join(errs, "\n"))
+	}
+
+	var files []file
+	for _, pf := range resp.Files {
+		// 插件只能在 Out 目录下生成文件
+		if pf.Path == "" || path.IsAbs(pf.Path) || !fs.ValidPath(path.Clean(pf.Path)) {
+			return nil, nil, fmt.Errorf("plugin %s: invalid file path %q", p.Name, pf.Path)
+		}
+		files = append(files, file{
+			path:     path.Join(filepath.ToSlash(p.Out), pf.Path),
+			content:  []byte(pf.Content),
+			template: "plugin:" + p.Name,
+			mode:     pf.Mode.Perm(),
+			policy:   policy,
+		})
+	}
+	return files, diags, nil
+}
@@ -0,0 +1,107 @@
+package gen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ychengcloud/cre/spec"
+)
+
+// TestPluginProcess is not a real test, 作为插件进程被 TestPlugin 调用
+func TestPluginProcess(t *testing.T) {
+	mode := os.Getenv("CRE_TEST_PLUGIN")
+	if mode == "" {
+		t.Skip("run as a plugin by TestPlugin")
+	}
+
+	req := &PluginRequest{}
+	if err := json.NewDecoder(os.Stdin).Decode(req); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	schema, err := req.Snapshot.Restore()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	resp := &PluginResponse{}
+	switch mode {
+	case "ok":
+		for _, table := range schema.Tables() {
+			resp.Files = append(resp.Files, &PluginFile{
+				Path:    table.Name + ".ts",
+				Content: fmt.Sprintf("// %s v%d\nexport interface %s {}\n", req.Attrs["prefix"], req.Version, table.Name),
+			})
+		}
+		resp.Files = append(resp.Files, &PluginFile{Path: "run.sh", Content: "#!/bin/sh\n", Mode: 0755})
+		resp.Diagnostics = append(resp.Diagnostics, &Diagnostic{Severity: SeverityWarning, Path: "user.ts", Message: "no comment"})
+	case "error":
+		resp.Diagnostics = append(resp.Diagnostics, &Diagnostic{Severity: SeverityError, Message: "unsupported type"})
+	case "escape":
+		resp.Files = append(resp.Files, &PluginFile{Path: "../escape.ts"})
+	case "exit":
+		fmt.Fprintln(os.Stderr, "boom")
+		os.Exit(1)
+	}
+	json.NewEncoder(os.Stdout).Encode(resp)
+	os.Exit(0)
+}
+
+func TestPlugin(t *testing.T) {
+	tests := []struct {
+		mode string
+		err  string
+	}{
+		{mode: "ok"},
+		{mode: "error", err: "unsupported type"},
+		{mode: "escape", err: `invalid file path "../escape.ts"`},
+		{mode: "exit", err: "boom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			r := require.New(t)
+			t.Setenv("CRE_TEST_PLUGIN", tt.mode)
+
+			schema := &spec.Schema{Name: "main"}
+			table := &spec.Table{Name: "user"}
+			table.AddFields(&spec.Field{Name: "id", Type: &spec.IntegerType{Name: "int", Size: 64}, PrimaryKey: true})
+			table.ID = table.GetField("id")
+			schema.AddTables(table)
+
+			g, err := NewGenerator(&Config{
+				Root:  t.TempDir(),
+				Attrs: map[string]any{"prefix": "cre"},
+				Plugins: []*Plugin{{
+					Name: os.Args[0],
+					Args: []string{"-test.run=^TestPluginProcess$"},
+					Out:  "web/",
+				}},
+			}, &schemaLoader{schema: schema})
+			r.NoError(err)
+			sink := NewMemSink()
+			g.Sink = sink
+
+			err = g.Generate(context.Background())
+			if tt.err != "" {
+				r.ErrorContains(err, tt.err)
+				r.Empty(sink.FS)
+				return
+			}
+			r.NoError(err)
+			r.Equal("// cre v1\nexport interface user {}\n", string(sink.FS["web/user.ts"].Data))
+			r.Equal(os.FileMode(0755), sink.FS["web/run.sh"].Mode)
+			r.Len(g.Report().Diagnostics, 1)
+			r.Equal(os.Args[0]+": warning: user.ts: no comment", g.Report().Diagnostics[0].String())
+
+			m, err := readManifest(sink)
+			r.NoError(err)
+			r.Equal("plugin:"+os.Args[0], m.Files[0].Template)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+)
+
+// 保护区域，区域内的内容在重新生成时保留
+//
+//	// cre:begin custom-methods
+//	func (u *User) FullName() string { ... }
+//	// cre:end
+//
+// 标记可以使用任意注释风格，如 # cre:begin name 或 <!-- cre:begin name -->
+var (
+	regionBeginRe = regexp.MustCompile(`\bcre:begin\s+([\w.\-]+)`)
+	regionEndRe   = regexp.MustCompile(`\bcre:end\b`)
+)
+
+// OrphanedRegion is a protected region of the existing file which is no longer
+// emitted by the template, 其内容不会写入新文件.
+type OrphanedRegion struct {
+	Path    string
+	Name    string
+	Content string
+}
+
+type region struct {
+	name string
+	body []byte
+}
+
+// lines splits the content into lines, 保留换行符
+func lines(content []byte) [][]byte {
+	return bytes.SplitAfter(content, []byte("\n"))
+}
+
+// parseRegions returns the protected regions in order of appearance.
+func parseRegions(content []byte) ([]*region, error) {
+	var (
+		regions []*region
+		current *region
+		seen    = make(map[string]bool)
+	)
+	for i, line := range lines(content) {
+		if m := regionBeginRe.FindSubmatch(line); m != nil {
+			if current != nil {
+				return nil, fmt.Errorf("line %d: region %q is nested in region %q", i+1, m[1], current.name)
+			}
+			name := string(m[1])
+			if seen[name] {
+				return nil, fmt.Errorf("line %d: duplicate region %q", i+1, name)
+			}
+			seen[name] = true
+			current = &region{name: name}
+			continue
+		}
+		if regionEndRe.Match(line) {
+			if current == nil {
+				return nil, fmt.Errorf("line %d: cre:end without cre:begin", i+1)
+			}
+			regions = append(regions, current)
+			current = nil
+			continue
+		}
+		if current != nil {
+			current.body = append(current.body, line...)
+		}
+	}
+	if current != nil {
+		return nil, fmt.Errorf("region %q is not closed", current.name)
+	}
+	return regions, nil
+}
+
+// mergeRegions carries the content of the protected regions in the existing file over
+// into the rendered content, 返回新内容中已不存在的区域
+func mergeRegions(rendered, existing []byte) ([]byte, []*region, error) {
+	old, err := parseRegions(existing)
+	if err != nil {
+		return nil, nil, fmt.Errorf("existing file: %w", err)
+	}
+	if len(old) == 0 {
+		return rendered, nil, nil
+	}
+	if _, err := parseRegions(rendered); err != nil {
+		return nil, nil, fmt.Errorf("rendered file: %w", err)
+	}
+
+	bodies := make(map[string][]byte, len(old))
+	for _, r := range old {
+		bodies[r.name] = r.body
+	}
+
+	var (
+		b       bytes.Buffer
+		used    = make(map[string]bool)
+		current string
+		inside  bool
+	)
+	for _, line := range lines(rendered) {
+		if m := regionBeginRe.FindSubmatch(line); m != nil {
+			b.Write(line)
+			current, inside = string(m[1]), true
+			if body, ok := bodies[current]; ok {
+				b.Write(body)
+				used[current] = true
+			}
+			continue
+		}
+		if inside && regionEndRe.Match(line) {
+			inside = false
+			b.Write(line)
+			continue
+		}
+		// 已保留旧内容的区域忽略模板中的默认内容
+		if inside && used[current] {
+			continue
+		}
+		b.Write(line)
+	}
+
+	var orphaned []*region
+	for _, r := range old {
+		if !used[r.name] {
+			orphaned = append(orphaned, r)
+		}
+	}
+	return b.Bytes(), orphaned, nil
+}
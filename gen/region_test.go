@@ -0,0 +1,78 @@
+package gen
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRegions(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []*region
+		wantErr  bool
+	}{
+		{name: "none", content: "package p\n"},
+		{
+			name:    "regions",
+			content: "a\n// cre:begin methods\nfunc f() {}\n// cre:end\n<!-- cre:begin html -->\n<p></p>\n<!-- cre:end -->\n# cre:begin empty\n# cre:end\n",
+			expected: []*region{
+				{name: "methods", body: []byte("func f() {}\n")},
+				{name: "html", body: []byte("<p></p>\n")},
+				{name: "empty"},
+			},
+		},
+		{name: "nested", content: "// cre:begin a\n// cre:begin b\n// cre:end\n// cre:end\n", wantErr: true},
+		{name: "duplicate", content: "// cre:begin a\n// cre:end\n// cre:begin a\n// cre:end\n", wantErr: true},
+		{name: "end without begin", content: "// cre:end\n", wantErr: true},
+		{name: "not closed", content: "// cre:begin a\n", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := parseRegions([]byte(test.content))
+			require.Equal(t, test.wantErr, err != nil, err)
+			require.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestMergeRegions(t *testing.T) {
+	rendered := "package p\n\n// cre:begin methods\n// TODO\n// cre:end\n\n// cre:begin imports\n// cre:end\n"
+	existing := "package p\n\n// cre:begin methods\nfunc (u *User) Name() string { return u.name }\n// cre:end\n\n// cre:begin removed\nvar x = 1\n// cre:end\n"
+
+	r := require.New(t)
+	merged, orphaned, err := mergeRegions([]byte(rendered), []byte(existing))
+	r.NoError(err)
+	r.Equal("package p\n\n// cre:begin methods\nfunc (u *User) Name() string { return u.name }\n// cre:end\n\n// cre:begin imports\n// cre:end\n", string(merged))
+	r.Equal([]*region{{name: "removed", body: []byte("var x = 1\n")}}, orphaned)
+
+	// 旧文件没有保护区域时保持新内容
+	merged, orphaned, err = mergeRegions([]byte(rendered), []byte("package p\n"))
+	r.NoError(err)
+	r.Equal(rendered, string(merged))
+	r.Empty(orphaned)
+
+	_, _, err = mergeRegions([]byte(rendered), []byte("// cre:begin a\n"))
+	r.Error(err)
+}
+
+func TestWriteRegions(t *testing.T) {
+	r := require.New(t)
+	sink := NewMemSink()
+	r.NoError(sink.WriteFiles([]*SinkFile{
+		{Path: "user.go", Content: []byte("package p\n\n// cre:begin methods\nfunc Hello() {}\n// cre:end\n\n// cre:begin old\nfunc Old() {}\n// cre:end\n")},
+	}))
+
+	a := assets{files: []file{
+		{path: "user.go", content: []byte("package p\n\nvar Version = 2\n\n// cre:begin methods\n// cre:end\n"), policy: WriteAlways},
+	}}
+	report, err := a.prepare(context.Background(), sink)
+	r.NoError(err)
+	r.NoError(sink.WriteFiles(a.sinkFiles()))
+	r.Equal([]*OrphanedRegion{{Path: "user.go", Name: "old", Content: "func Old() {}\n"}}, report.Orphaned)
+
+	// goimports 格式化后的结果
+	r.Equal("package p\n\nvar Version = 2\n\n// cre:begin methods\nfunc Hello() {}\n\n// cre:end\n", string(sink.FS["user.go"].Data))
+}
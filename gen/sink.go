@@ -0,0 +1,310 @@
+package gen
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing/fstest"
+	"time"
+)
+
+// Sink is the destination of the generated files, 路径为相对于输出根目录的 / 分隔路径
+type Sink interface {
+	// ReadFile returns the existing content of the file, 不存在时返回 fs.ErrNotExist
+	ReadFile(name string) ([]byte, error)
+	// WriteFiles writes all files and removes the files marked Delete, 任一文件写入失败时不应保留部分结果
+	WriteFiles(files []*SinkFile) error
+	// Remove removes the file.
+	Remove(name string) error
+}
+
+// SinkFile is a file to be written to the sink.
+type SinkFile struct {
+	Path    string
+	Content []byte
+	Mode    fs.FileMode // 文件权限，为 0 时使用 0644
+	Delete  bool        // 删除文件，用于 Config.Prune，与写入的文件一起提交
+}
+
+func (f *SinkFile) perm() fs.FileMode {
+	if f.Mode == 0 {
+		return 0644
+	}
+	return f.Mode.Perm()
+}
+
+// DiskSink writes the files under the root directory of the local disk,
+// 先写入 .cre 下的临时目录，全部成功后再替换到目标路径，替换失败时恢复原有文件和删除的文件，并删除新建的目录
+type DiskSink struct {
+	Root string
+}
+
+func NewDiskSink(root string) *DiskSink {
+	return &DiskSink{Root: root}
+}
+
+func (s *DiskSink) path(name string) string {
+	return filepath.Join(s.Root, filepath.FromSlash(name))
+}
+
+func (s *DiskSink) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(s.path(name))
+}
+
+func (s *DiskSink) Remove(name string) error {
+	if err := os.Remove(s.path(name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *DiskSink) WriteFiles(files []*SinkFile) error {
+	if err := os.MkdirAll(s.path(".cre"), os.ModePerm); err != nil {
+		return err
+	}
+	staging, err := os.MkdirTemp(s.path(".cre"), "staging-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+
+	var staged []*stagedFile
+	for i, f := range files {
+		sf := &stagedFile{
+			path:   s.path(f.Path),
+			tmp:    filepath.Join(staging, strconv.Itoa(i)),
+			backup: filepath.Join(staging, strconv.Itoa(i)+".bak"),
+			delete: f.Delete,
+		}
+		if f.Delete {
+			staged = append(staged, sf)
+			continue
+		}
+		if err := os.WriteFile(sf.tmp, f.Content, f.perm()); err != nil {
+			return fmt.Errorf("write file %q: %w", f.Path, err)
+		}
+		// 不受 umask 影响
+		if err := os.Chmod(sf.tmp, f.perm()); err != nil {
+			return err
+		}
+		staged = append(staged, sf)
+	}
+
+	for i, sf := range staged {
+		if err := sf.swap(); err != nil {
+			for j := i; j >= 0; j-- {
+				staged[j].rollback()
+			}
+			return fmt.Errorf("write file %q: %w", files[i].Path, err)
+		}
+	}
+	return nil
+}
+
+// stagedFile is a file written into the staging directory.
+type stagedFile struct {
+	path    string
+	tmp     string   // 新内容
+	backup  string   // 原有文件
+	delete  bool     // 只删除原有文件
+	dirs    []string // swap 时新建的目录，由内向外
+	backed  bool
+	swapped bool
+}
+
+// swap moves the original file to the backup and the new file into place.
+func (sf *stagedFile) swap() error {
+	if !sf.delete {
+		if err := sf.mkdirs(); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(sf.path, sf.backup); err == nil {
+		sf.backed = true
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if sf.delete {
+		return nil
+	}
+	if err := os.Rename(sf.tmp, sf.path); err != nil {
+		return err
+	}
+	sf.swapped = true
+	return nil
+}
+
+// rollback restores the original file.
+func (sf *stagedFile) rollback() {
+	if sf.swapped {
+		os.Remove(sf.path)
+	}
+	if sf.backed {
+		os.Rename(sf.backup, sf.path)
+	}
+	// 目录中还有其他文件时删除失败，保留
+	for _, dir := range sf.dirs {
+		os.Remove(dir)
+	}
+}
+
+// mkdirs creates the parent directories of the file and records the created ones for rollback.
+func (sf *stagedFile) mkdirs() error {
+	dir := filepath.Dir(sf.path)
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil || !errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		sf.dirs = append(sf.dirs, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	return os.MkdirAll(dir, os.ModePerm)
+}
+
+// MemSink keeps the files in memory, FS 可直接作为 fs.FS 使用
+type MemSink struct {
+	FS fstest.MapFS
+}
+
+func NewMemSink() *MemSink {
+	return &MemSink{FS: fstest.MapFS{}}
+}
+
+func (s *MemSink) ReadFile(name string) ([]byte, error) {
+	f, ok := s.FS[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	return f.Data, nil
+}
+
+func (s *MemSink) WriteFiles(files []*SinkFile) error {
+	now := time.Now()
+	for _, f := range files {
+		if f.Delete {
+			delete(s.FS, f.Path)
+			continue
+		}
+		s.FS[f.Path] = &fstest.MapFile{Data: f.Content, Mode: f.perm(), ModTime: now}
+	}
+	return nil
+}
+
+func (s *MemSink) Remove(name string) error {
+	delete(s.FS, name)
+	return nil
+}
+
+// archiveSink is the base of the sinks writing a new archive, 不存在已有文件，也不写入 manifest
+type archiveSink struct{}
+
+// manifestless is implemented by the sinks which do not keep the manifest, 每次生成的都是完整的新归档
+type manifestless interface {
+	manifestless()
+}
+
+func (archiveSink) manifestless() {}
+
+func (archiveSink) ReadFile(name string) ([]byte, error) {
+	return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+}
+
+func (archiveSink) Remove(name string) error {
+	return nil
+}
+
+// ZipSink writes the files as a zip archive.
+type ZipSink struct {
+	archiveSink
+	w io.Writer
+}
+
+func NewZipSink(w io.Writer) *ZipSink {
+	return &ZipSink{w: w}
+}
+
+func (s *ZipSink) WriteFiles(files []*SinkFile) error {
+	zw := zip.NewWriter(s.w)
+	now := time.Now()
+	for _, f := range sortedSinkFiles(files) {
+		hdr := &zip.FileHeader{Name: f.Path, Method: zip.Deflate, Modified: now}
+		hdr.SetMode(f.perm())
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(f.Content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// TarSink writes the files as a tar stream.
+type TarSink struct {
+	archiveSink
+	w io.Writer
+}
+
+func NewTarSink(w io.Writer) *TarSink {
+	return &TarSink{w: w}
+}
+
+func (s *TarSink) WriteFiles(files []*SinkFile) error {
+	tw := tar.NewWriter(s.w)
+	now := time.Now()
+	for _, f := range sortedSinkFiles(files) {
+		hdr := &tar.Header{Name: f.Path, Mode: int64(f.perm()), Size: int64(len(f.Content)), ModTime: now}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(f.Content); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// StdoutSink prints the files one after another, 每个文件前输出 ==> path <==，二进制文件仅输出大小
+type StdoutSink struct {
+	archiveSink
+	w io.Writer
+}
+
+func NewStdoutSink(w io.Writer) *StdoutSink {
+	return &StdoutSink{w: w}
+}
+
+func (s *StdoutSink) WriteFiles(files []*SinkFile) error {
+	for _, f := range sortedSinkFiles(files) {
+		content := f.Content
+		if isBinary(content) {
+			content = []byte(fmt.Sprintf("(binary, %d bytes)\n", len(content)))
+		}
+		if _, err := fmt.Fprintf(s.w, "==> %s <==\n%s\n", f.Path, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedSinkFiles(files []*SinkFile) []*SinkFile {
+	// 归档中没有已有文件，忽略删除
+	var sorted []*SinkFile
+	for _, f := range files {
+		if !f.Delete {
+			sorted = append(sorted, f)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+	return sorted
+}
@@ -0,0 +1,202 @@
+package gen
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ychengcloud/cre"
+	"github.com/ychengcloud/cre/spec"
+)
+
+type schemaLoader struct {
+	schema *spec.Schema
+}
+
+func (l *schemaLoader) Load(ctx context.Context, name string) (*spec.Schema, error) {
+	return l.schema, nil
+}
+
+func (l *schemaLoader) Dialect() string {
+	return cre.SQLite
+}
+
+// newMemGenerator returns the generator of the schema with the templates in root, 生成到新的 MemSink
+func newMemGenerator(t testing.TB, cfg *Config, schema *spec.Schema, root fs.FS, opts ...Option) (*Generator, *MemSink) {
+	g, err := NewGenerator(cfg, &schemaLoader{schema: schema}, append([]Option{WithTemplateFS(root)}, opts...)...)
+	require.NoError(t, err)
+	sink := NewMemSink()
+	g.Sink = sink
+	return g, sink
+}
+
+// generateMem generates the files into a MemSink and returns their contents.
+func generateMem(t testing.TB, cfg *Config, schema *spec.Schema, root fs.FS, opts ...Option) map[string]string {
+	g, sink := newMemGenerator(t, cfg, schema, root, opts...)
+	require.NoError(t, g.Generate(context.Background()))
+	return memFiles(sink)
+}
+
+// memFiles returns the contents of the files in the sink, 不包括 manifest
+func memFiles(sink *MemSink) map[string]string {
+	files := make(map[string]string)
+	for path, f := range sink.FS {
+		if path != ManifestPath {
+			files[path] = string(f.Data)
+		}
+	}
+	return files
+}
+
+func TestGenerateSink(t *testing.T) {
+	r := require.New(t)
+
+	root := t.TempDir()
+	r.NoError(os.WriteFile(filepath.Join(root, "table.tmpl"), []byte("{{ .Table.Name }}\n"), 0644))
+
+	newGenerator := func(tables ...string) *Generator {
+		schema := &spec.Schema{Name: "main"}
+		for _, name := range tables {
+			table := &spec.Table{Name: name}
+			table.AddFields(&spec.Field{Name: "id", Type: &spec.IntegerType{Name: "int", Size: 64}, PrimaryKey: true})
+			table.ID = table.GetField("id")
+			schema.AddTables(table)
+		}
+		g, err := NewGenerator(&Config{
+			Root:      root,
+			Prune:     true,
+			Templates: []*Template{{Path: "table.tmpl", GenPath: "tables", Format: "{{ .Table.Name }}.txt", Mode: TplModeMulti}},
+		}, &schemaLoader{schema: schema})
+		r.NoError(err)
+		return g
+	}
+
+	sink := NewMemSink()
+	g := newGenerator("user", "post")
+	g.Sink = sink
+	r.NoError(g.Generate(context.Background()))
+	r.Equal("user\n", string(sink.FS["tables/user.txt"].Data))
+	r.Contains(sink.FS, "tables/post.txt")
+	r.Contains(sink.FS, ManifestPath)
+
+	g = newGenerator("user")
+	g.Sink = sink
+	r.NoError(g.Generate(context.Background()))
+	r.Equal([]string{"tables/post.txt"}, g.Report().Pruned)
+	r.NotContains(sink.FS, "tables/post.txt")
+
+	var b bytes.Buffer
+	g = newGenerator("user", "post")
+	g.Sink = NewZipSink(&b)
+	r.NoError(g.Generate(context.Background()))
+	zr, err := zip.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	r.NoError(err)
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	// 归档中不包含 manifest
+	r.Equal([]string{"tables/post.txt", "tables/user.txt"}, names)
+
+	b.Reset()
+	g = newGenerator("user")
+	g.Sink = NewStdoutSink(&b)
+	r.NoError(g.Generate(context.Background()))
+	r.Equal("==> tables/user.txt <==\nuser\n\n", b.String())
+}
+
+func TestDiskSink(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	path := func(name string) string { return filepath.Join(dir, name) }
+	r.NoError(os.WriteFile(path("a.go"), []byte("package old\n"), 0644))
+	r.NoError(os.WriteFile(path("blocker"), []byte("file\n"), 0644))
+
+	sink := NewDiskSink(dir)
+	r.NoError(sink.WriteFiles([]*SinkFile{
+		{Path: "multi/user.go", Content: []byte("package multi\n")},
+		{Path: "run.sh", Content: []byte("#!/bin/sh\n"), Mode: 0755},
+	}))
+	content, err := sink.ReadFile("multi/user.go")
+	r.NoError(err)
+	r.Equal("package multi\n", string(content))
+	info, err := os.Stat(path("run.sh"))
+	r.NoError(err)
+	r.Equal(os.FileMode(0755), info.Mode().Perm())
+
+	// 替换失败时恢复已替换和删除的文件，删除新建的目录
+	r.Error(sink.WriteFiles([]*SinkFile{
+		{Path: "a.go", Content: []byte("package p\n")},
+		{Path: "new.go", Content: []byte("package p\n")},
+		{Path: "run.sh", Delete: true},
+		{Path: "new/dir/d.go", Content: []byte("package dir\n")},
+		{Path: "blocker/c.go", Content: []byte("package p\n")},
+	}))
+
+	content, err = os.ReadFile(path("a.go"))
+	r.NoError(err)
+	r.Equal("package old\n", string(content))
+	r.NoFileExists(path("new.go"))
+	r.FileExists(path("run.sh"))
+	r.NoDirExists(path("new"))
+	entries, err := os.ReadDir(path(".cre"))
+	r.NoError(err)
+	r.Empty(entries)
+
+	r.NoError(sink.WriteFiles([]*SinkFile{{Path: "run.sh", Delete: true}, {Path: "gone.go", Delete: true}}))
+	r.NoFileExists(path("run.sh"))
+
+	r.NoError(sink.Remove("multi/user.go"))
+	r.NoError(sink.Remove("multi/user.go"))
+	r.NoFileExists(path("multi/user.go"))
+}
+
+func TestArchiveSinks(t *testing.T) {
+	r := require.New(t)
+	files := []*SinkFile{
+		{Path: "multi/user.go", Content: []byte("package multi\n")},
+		{Path: "main.go", Content: []byte("package main\n")},
+	}
+
+	var b bytes.Buffer
+	r.NoError(NewZipSink(&b).WriteFiles(files))
+	zr, err := zip.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	r.NoError(err)
+	r.Len(zr.File, 2)
+	r.Equal("main.go", zr.File[0].Name)
+	f, err := zr.File[1].Open()
+	r.NoError(err)
+	content, err := io.ReadAll(f)
+	r.NoError(err)
+	r.Equal("package multi\n", string(content))
+
+	b.Reset()
+	r.NoError(NewTarSink(&b).WriteFiles(files))
+	tr := tar.NewReader(&b)
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		r.NoError(err)
+		names = append(names, hdr.Name)
+	}
+	r.Equal([]string{"main.go", "multi/user.go"}, names)
+
+	b.Reset()
+	sink := NewStdoutSink(&b)
+	r.NoError(sink.WriteFiles(files))
+	r.Equal("==> main.go <==\npackage main\n\n==> multi/user.go <==\npackage multi\n\n", b.String())
+	_, err = sink.ReadFile("main.go")
+	r.ErrorIs(err, os.ErrNotExist)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ychengcloud/cre/gen"
+)
+
+func BenchmarkGenerate(b *testing.B) {
+	// jobs 为 0 时使用 CPU 数
+	for _, jobs := range []int{1, 0} {
+		b.Run(fmt.Sprintf("jobs=%d", jobs), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				cfg := config()
+				cfg.Jobs = jobs
+				g, err := gen.NewGenerator(cfg, NewFakeLoader())
+				if err != nil {
+					b.Fatal(err)
+				}
+				g.Sink = gen.NewMemSink()
+				if err := g.Generate(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkRender 对比只渲染一次(once)与渲染两次获取 import(twice)的方式
+// templates 中的模板将 .ImportPkg 赋值给变量，按两次渲染; once 改为直接输出 receiver
+func BenchmarkRender(b *testing.B) {
+	once, err := onceTemplates("templates")
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, bench := range []struct {
+		name string
+		fsys fs.FS
+	}{
+		{name: "once", fsys: once},
+		{name: "twice", fsys: os.DirFS("templates")},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				g, err := gen.NewGenerator(config(), NewFakeLoader(), gen.WithTemplateFS(bench.fsys))
+				if err != nil {
+					b.Fatal(err)
+				}
+				g.Sink = gen.NewMemSink()
+				if err := g.Generate(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// onceTemplates returns the templates in dir which print receiver directly
+func onceTemplates(dir string) (fs.FS, error) {
+	r := strings.NewReplacer(
+		"{{ $importPkg := .ImportPkg }}", "",
+		"receiver  $importPkg", "receiver $.ImportPkg",
+		"receiver $importPkg", "receiver $.ImportPkg",
+	)
+	fsys := fstest.MapFS{}
+	err := fs.WalkDir(os.DirFS(dir), ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		src, err := os.ReadFile(dir + "/" + path)
+		if err != nil {
+			return err
+		}
+		fsys[path] = &fstest.MapFile{Data: []byte(r.Replace(string(src)))}
+		return nil
+	})
+	return fsys, err
+}
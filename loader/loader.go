@@ -4,20 +4,26 @@ package loader
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 
 	"github.com/ychengcloud/cre"
 	"github.com/ychengcloud/cre/loader/sql"
+	"github.com/ychengcloud/cre/loader/sql/ddl"
 	"github.com/ychengcloud/cre/loader/sql/mysql"
 	"github.com/ychengcloud/cre/loader/sql/postgres"
+	"github.com/ychengcloud/cre/loader/sql/sqlite"
 	"github.com/ychengcloud/cre/spec"
 )
 
 type SQLLoader struct {
-	driver cre.Driver
+	driver  cre.Driver
+	schemas []string
 }
 
-func NewSQLLoader(driver cre.Driver) *SQLLoader {
-	return &SQLLoader{driver: driver}
+// NewSQLLoader returns the loader of the database, schemas 仅对 Postgres 有效
+func NewSQLLoader(driver cre.Driver, schemas ...string) *SQLLoader {
+	return &SQLLoader{driver: driver, schemas: schemas}
 }
 func (l *SQLLoader) Load(ctx context.Context, name string) (*spec.Schema, error) {
 	var i sql.Inspector
@@ -25,7 +31,9 @@ func (l *SQLLoader) Load(ctx context.Context, name string) (*spec.Schema, error)
 	case cre.MySQL:
 		i = mysql.NewInspector(l.driver)
 	case cre.Postgres:
-		i = postgres.NewInspector(l.driver)
+		i = postgres.NewInspector(l.driver, l.schemas...)
+	case cre.SQLite:
+		i = sqlite.NewInspector(l.driver)
 	default:
 		return nil, fmt.Errorf("load: unsupported dialect: %v", l.driver.Dialect())
 	}
@@ -46,10 +54,80 @@ func (l *SQLLoader) Dialect() string {
 	return l.driver.Dialect()
 }
 
+// DDLLoader loads the schema from DDL scripts instead of a live database.
+type DDLLoader struct {
+	dialect  string
+	patterns []string
+}
+
+// NewDDLLoader returns a loader which parses the files matched by the glob patterns,
+// 匹配的文件按文件名顺序解析，如 ./migrations/*.sql
+func NewDDLLoader(dialect string, patterns ...string) *DDLLoader {
+	return &DDLLoader{dialect: dialect, patterns: patterns}
+}
+
+func (l *DDLLoader) Load(ctx context.Context, name string) (*spec.Schema, error) {
+	var files []string
+	for _, pattern := range l.patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("load: invalid ddl pattern %q: %w", pattern, err)
+		}
+		files = append(files, matches...)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("load: no ddl file matches %v", l.patterns)
+	}
+
+	schema, err := ddl.NewInspector(l.dialect, files...).Inspect(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return schema.Convert()
+}
+
+func (l *DDLLoader) Dialect() string {
+	return l.dialect
+}
+
+// SnapshotLoader loads the schema from a snapshot file written by spec.MarshalSnapshot.
+type SnapshotLoader struct {
+	dialect string
+	path    string
+}
+
+// NewSnapshotLoader returns a loader which reads the JSON or YAML snapshot.
+func NewSnapshotLoader(dialect string, path string) *SnapshotLoader {
+	return &SnapshotLoader{dialect: dialect, path: path}
+}
+
+// Load reads the snapshot, the schema name recorded in the snapshot takes precedence.
+func (l *SnapshotLoader) Load(ctx context.Context, name string) (*spec.Schema, error) {
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return nil, fmt.Errorf("load: read snapshot: %w", err)
+	}
+
+	schema, err := spec.UnmarshalSnapshot(data)
+	if err != nil {
+		return nil, err
+	}
+	if schema.Name == "" {
+		schema.Name = name
+	}
+	return schema, nil
+}
+
+func (l *SnapshotLoader) Dialect() string {
+	return l.dialect
+}
+
 type LoaderOption func(*loaderOptions)
 
 type loaderOptions struct {
 	resources []string
+	schemas   []string
 }
 
 func WithResources(resources ...string) LoaderOption {
@@ -58,6 +136,13 @@ func WithResources(resources ...string) LoaderOption {
 	}
 }
 
+// WithSchemas sets the postgres schemas to load, 默认仅加载 CURRENT_SCHEMA()
+func WithSchemas(schemas ...string) LoaderOption {
+	return func(l *loaderOptions) {
+		l.schemas = append(l.schemas, schemas...)
+	}
+}
+
 func NewLoader(driver cre.Driver, opts ...LoaderOption) (cre.Loader, error) {
 	lo := loaderOptions{}
 
@@ -65,8 +150,8 @@ func NewLoader(driver cre.Driver, opts ...LoaderOption) (cre.Loader, error) {
 		apply(&lo)
 	}
 	switch driver.Dialect() {
-	case cre.MySQL, cre.Postgres:
-		return NewSQLLoader(driver), nil
+	case cre.MySQL, cre.Postgres, cre.SQLite:
+		return NewSQLLoader(driver, lo.schemas...), nil
 	default:
 		return nil, fmt.Errorf("load/create: unsupported dialect: %v", driver.Dialect())
 	}
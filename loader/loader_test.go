@@ -1,7 +1,62 @@
 package loader
 
-import "testing"
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ychengcloud/cre"
+	"github.com/ychengcloud/cre/spec"
+)
 
 func TestSQLLoader(t *testing.T) {
 
 }
+
+func TestDDLLoader(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "001_user.sql"), []byte("CREATE TABLE `user` (`id` bigint NOT NULL AUTO_INCREMENT PRIMARY KEY, `name` varchar(64));"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "002_user_email.sql"), []byte("ALTER TABLE `user` ADD COLUMN `email` varchar(64) UNIQUE;"), 0644))
+
+	l := NewDDLLoader(cre.MySQL, filepath.Join(dir, "*.sql"))
+	require.Equal(t, cre.MySQL, l.Dialect())
+
+	s, err := l.Load(context.Background(), "test")
+	require.NoError(t, err)
+	require.Equal(t, "test", s.Name)
+
+	table := s.Table("user")
+	require.NotNil(t, table)
+	require.Len(t, table.Fields(), 3)
+	require.Equal(t, "id", table.ID.Name)
+	require.True(t, table.GetField("email").Unique)
+
+	_, err = NewDDLLoader(cre.MySQL, filepath.Join(dir, "*.ddl")).Load(context.Background(), "test")
+	require.Error(t, err)
+}
+
+func TestSnapshotLoader(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "001_user.sql"), []byte("CREATE TABLE `user` (`id` bigint NOT NULL AUTO_INCREMENT PRIMARY KEY, `name` varchar(64));"), 0644))
+
+	expected, err := NewDDLLoader(cre.MySQL, filepath.Join(dir, "*.sql")).Load(context.Background(), "test")
+	require.NoError(t, err)
+
+	data, err := spec.MarshalSnapshot(expected, spec.SnapshotYAML)
+	require.NoError(t, err)
+	path := filepath.Join(dir, "schema.yaml")
+	require.NoError(t, os.WriteFile(path, data, 0644))
+
+	l := NewSnapshotLoader(cre.MySQL, path)
+	require.Equal(t, cre.MySQL, l.Dialect())
+
+	actual, err := l.Load(context.Background(), "")
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+
+	_, err = NewSnapshotLoader(cre.MySQL, filepath.Join(dir, "none.json")).Load(context.Background(), "")
+	require.Error(t, err)
+}
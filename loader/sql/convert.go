@@ -1,6 +1,9 @@
 package sql
 
 import (
+	"fmt"
+	"sort"
+
 	"github.com/ychengcloud/cre/spec"
 )
 
@@ -17,13 +20,57 @@ func (s *Schema) Convert() (*spec.Schema, error) {
 		}
 		schema.AddTables(table)
 	}
+
+	// 外键引用其他表的字段，所有表转换完成后再处理
+	for _, t := range s.Tables {
+		if err := t.convertForeignKeys(schema); err != nil {
+			return nil, err
+		}
+	}
 	return schema, nil
 }
 
+func (t *Table) convertForeignKeys(s *spec.Schema) error {
+	table := s.Table(t.QualifiedName())
+	for _, fk := range t.ForeignKeys {
+		if fk.RefTable == nil {
+			return fmt.Errorf("convert: ref table not found for fk %q", fk.Name)
+		}
+		rt := s.Table(fk.RefTable.QualifiedName())
+		if rt == nil {
+			return fmt.Errorf("convert: ref table %q not found for fk %q", fk.RefTable.QualifiedName(), fk.Name)
+		}
+
+		foreignKey := &spec.ForeignKey{
+			Name:     fk.Name,
+			RefTable: rt,
+			OnDelete: spec.ReferenceOption(fk.OnDelete),
+			OnUpdate: spec.ReferenceOption(fk.OnUpdate),
+		}
+		for _, c := range fk.Columns {
+			f := table.GetField(c.Name)
+			if f == nil {
+				return fmt.Errorf("convert: column %q not found for fk %q", c.Name, fk.Name)
+			}
+			foreignKey.Fields = append(foreignKey.Fields, f)
+		}
+		for _, c := range fk.RefColumns {
+			f := rt.GetField(c.Name)
+			if f == nil {
+				return fmt.Errorf("convert: ref column %q not found for fk %q", c.Name, fk.Name)
+			}
+			foreignKey.RefFields = append(foreignKey.RefFields, f)
+		}
+		table.ForeignKeys = append(table.ForeignKeys, foreignKey)
+	}
+	return nil
+}
+
 func (t *Table) convert() (*spec.Table, error) {
 	table := &spec.Table{
-		Name:    t.Name,
-		Comment: t.Comment,
+		Name:      t.Name,
+		Comment:   t.Comment,
+		Namespace: t.Namespace,
 	}
 	for _, c := range t.Columns {
 		field, err := c.convert()
@@ -33,9 +80,47 @@ func (t *Table) convert() (*spec.Table, error) {
 
 		table.AddFields(field)
 	}
+
+	for _, index := range t.Indexes {
+		idx, err := convertIndex(table, index)
+		if err != nil {
+			return nil, err
+		}
+		table.AddIndexes(idx)
+	}
 	return table, nil
 }
 
+func convertIndex(table *spec.Table, index *Index) (*spec.Index, error) {
+	idx := &spec.Index{
+		Name:    index.Name,
+		Unique:  index.Unique,
+		Primary: index.Primary,
+		Type:    index.Type,
+		Comment: index.Comment,
+	}
+
+	columns := make([]*IndexColumn, len(index.IndexColumns))
+	copy(columns, index.IndexColumns)
+	sort.SliceStable(columns, func(i, j int) bool {
+		return columns[i].SeqNo < columns[j].SeqNo
+	})
+	for _, ic := range columns {
+		part := &spec.IndexPart{Sub: ic.Sub}
+		if ic.Expr != nil {
+			part.Expr = fmt.Sprint(ic.Expr)
+		}
+		if ic.Column != "" {
+			// 没有对应字段时按表达式保留，如部分驱动返回的表达式索引的列名
+			if part.Field = table.GetField(ic.Column); part.Field == nil && part.Expr == "" {
+				part.Expr = ic.Column
+			}
+		}
+		idx.Parts = append(idx.Parts, part)
+	}
+	return idx, nil
+}
+
 func (c *Column) convert() (*spec.Field, error) {
 
 	fb := spec.Builder(c.Name).
@@ -46,7 +131,8 @@ func (c *Column) convert() (*spec.Field, error) {
 		Unique(c.Unique).
 		PrimaryKey(c.Primary).
 		AutoIncrement(c.AutoIncrement).
-		OnUpdate(c.OnUpdate)
+		OnUpdate(c.OnUpdate).
+		Generated(c.Generated)
 
 	convertIndexes(c, fb)
 	convertForeignKeys(c, fb)
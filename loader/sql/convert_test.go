@@ -149,6 +149,14 @@ func TestConvert(t *testing.T) {
 							},
 						},
 					},
+					{
+						Name: "idx2",
+						IndexColumns: []*IndexColumn{
+							{SeqNo: 2, Column: columns[0].Name},
+							{SeqNo: 1, Column: columns[1].Name, Sub: 8},
+							{SeqNo: 3, Expr: "(c1 + c2)"},
+						},
+					},
 				}
 
 				tables[0].Columns = columns
@@ -182,16 +190,44 @@ func TestConvert(t *testing.T) {
 						Nullable(true).
 						Comment("c2 comment").
 						PrimaryKey(true).
+						Index(true).
 						Filterable(true).
 						Sortable(true).
 						Build(),
 				)
+				c1, c2 := tables[0].GetField("c1"), tables[0].GetField("c2")
+				tables[0].AddIndexes(
+					&spec.Index{Name: "idx1", Unique: true, Parts: []*spec.IndexPart{{Field: c1}}},
+					&spec.Index{Name: "idx2", Parts: []*spec.IndexPart{{Field: c2, Sub: 8}, {Field: c1}, {Expr: "(c1 + c2)"}}},
+				)
 
 				schema := &spec.Schema{}
 				schema.AddTables(tables...)
 				return schema
 			},
 		},
+		{
+			// 没有对应字段的索引列按表达式保留
+			name: "convert expression indexes",
+			before: func() *Schema {
+				table := &Table{Name: "t1"}
+				table.Columns = []*Column{{Name: "email", Type: &spec.StringType{}, Table: table}}
+				table.Indexes = []*Index{{Name: "idx", IndexColumns: []*IndexColumn{
+					{SeqNo: 1, Column: "lower"},
+					{SeqNo: 2, Expr: "upper(email)"},
+				}}}
+				return &Schema{Tables: []*Table{table}}
+			},
+			expected: func() *spec.Schema {
+				table := &spec.Table{Name: "t1"}
+				table.AddFields(spec.Builder("email").Type(&spec.StringType{}).Build())
+				table.AddIndexes(&spec.Index{Name: "idx", Parts: []*spec.IndexPart{{Expr: "lower"}, {Expr: "upper(email)"}}})
+
+				schema := &spec.Schema{}
+				schema.AddTables(table)
+				return schema
+			},
+		},
 		{
 			name: "convert fks",
 			before: func() *Schema {
@@ -231,6 +267,8 @@ func TestConvert(t *testing.T) {
 						RefColumns: []*Column{
 							columns2[0],
 						},
+						OnUpdate: NoAction,
+						OnDelete: Cascade,
 					},
 				}
 
@@ -253,12 +291,49 @@ func TestConvert(t *testing.T) {
 
 				tables[0].AddFields(spec.Builder("c1").Type(&spec.IntegerType{}).ForeignKey(true).Build())
 				tables[1].AddFields(spec.Builder("c2").Type(&spec.IntegerType{}).Build())
+				tables[0].ForeignKeys = []*spec.ForeignKey{
+					{
+						Name:      "fk1",
+						Fields:    tables[0].Fields(),
+						RefTable:  tables[1],
+						RefFields: tables[1].Fields(),
+						OnDelete:  spec.Cascade,
+						OnUpdate:  spec.NoAction,
+					},
+				}
 
 				schema := &spec.Schema{}
 				schema.AddTables(tables...)
 				return schema
 			},
 		},
+		{
+			// 不同 schema 中的同名表按限定名称解析
+			name: "convert cross schema fks",
+			before: func() *Schema {
+				account := &Table{Name: "user", Namespace: "billing"}
+				user := &Table{Name: "user", Namespace: "auth"}
+				account.Columns = []*Column{{Name: "user_id", Type: &spec.IntegerType{}, Table: account}}
+				user.Columns = []*Column{{Name: "id", Type: &spec.IntegerType{}, Table: user}}
+				account.ForeignKeys = []*ForeignKey{
+					{Name: "fk", Table: account, Columns: account.Columns, RefTable: user, RefColumns: user.Columns},
+				}
+				return &Schema{Tables: []*Table{account, user}}
+			},
+			expected: func() *spec.Schema {
+				account := &spec.Table{Name: "user", Namespace: "billing"}
+				user := &spec.Table{Name: "user", Namespace: "auth"}
+				account.AddFields(spec.Builder("user_id").Type(&spec.IntegerType{}).ForeignKey(true).Build())
+				user.AddFields(spec.Builder("id").Type(&spec.IntegerType{}).Build())
+				account.ForeignKeys = []*spec.ForeignKey{
+					{Name: "fk", Fields: account.Fields(), RefTable: user, RefFields: user.Fields()},
+				}
+
+				schema := &spec.Schema{}
+				schema.AddTables(account, user)
+				return schema
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,138 @@
+package ddl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// cursor walks through the tokens of a statement.
+type cursor struct {
+	toks []token
+	pos  int
+}
+
+func newCursor(toks []token) *cursor {
+	return &cursor{toks: toks}
+}
+
+func (c *cursor) done() bool {
+	return c.pos >= len(c.toks)
+}
+
+// peekN returns the n-th token after the current one without consuming it.
+// 超出范围时返回空的标点
+func (c *cursor) peekN(n int) token {
+	if c.pos+n < len(c.toks) {
+		return c.toks[c.pos+n]
+	}
+	return token{kind: tkPunct}
+}
+
+func (c *cursor) peek() token {
+	return c.peekN(0)
+}
+
+func (c *cursor) next() token {
+	t := c.peek()
+	if !c.done() {
+		c.pos++
+	}
+	return t
+}
+
+// accept consumes the keyword sequence if all of them match.
+func (c *cursor) accept(keywords ...string) bool {
+	for i, kw := range keywords {
+		if !c.peekN(i).is(kw) {
+			return false
+		}
+	}
+	c.pos += len(keywords)
+	return true
+}
+
+// acceptPunct consumes the punctuation if it matches.
+func (c *cursor) acceptPunct(p string) bool {
+	if c.peek().isPunct(p) {
+		c.pos++
+		return true
+	}
+	return false
+}
+
+// isName reports whether the current token can be used as an identifier.
+func (c *cursor) isName() bool {
+	k := c.peek().kind
+	return k == tkIdent || k == tkQuoted
+}
+
+// group consumes a parenthesized group and returns the tokens inside it.
+func (c *cursor) group() ([]token, error) {
+	if !c.acceptPunct("(") {
+		return nil, fmt.Errorf("expect '(' but got %q", c.peek().text)
+	}
+	start := c.pos
+	depth := 1
+	for !c.done() {
+		t := c.next()
+		switch {
+		case t.isPunct("("):
+			depth++
+		case t.isPunct(")"):
+			depth--
+			if depth == 0 {
+				return c.toks[start : c.pos-1], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("unbalanced parentheses")
+}
+
+// rest consumes and returns all the remaining tokens.
+func (c *cursor) rest() []token {
+	toks := c.toks[c.pos:]
+	c.pos = len(c.toks)
+	return toks
+}
+
+// split splits the tokens by the top level commas.
+func split(toks []token) [][]token {
+	var (
+		parts [][]token
+		start int
+		depth int
+	)
+	for i, t := range toks {
+		switch {
+		case t.isPunct("("):
+			depth++
+		case t.isPunct(")"):
+			depth--
+		case t.isPunct(",") && depth == 0:
+			parts = append(parts, toks[start:i])
+			start = i + 1
+		}
+	}
+	if start < len(toks) {
+		parts = append(parts, toks[start:])
+	}
+	return parts
+}
+
+// rawText joins the tokens back into text, eg: varchar(255), now(), 'a'::text.
+func rawText(toks []token) string {
+	var b strings.Builder
+	for i, t := range toks {
+		if i > 0 {
+			prev := toks[i-1]
+			glued := prev.isPunct("(") || prev.isPunct("::") || prev.isPunct(".") ||
+				t.isPunct("(") || t.isPunct(")") || t.isPunct(",") || t.isPunct("::") || t.isPunct(".") ||
+				t.isPunct("[") || t.isPunct("]")
+			if !glued {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString(t.raw())
+	}
+	return b.String()
+}
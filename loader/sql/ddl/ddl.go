@@ -0,0 +1,58 @@
+// Package ddl inspects the schema from DDL scripts (migrations, dumps) instead of a live database.
+// CREATE TABLE, ALTER TABLE, CREATE INDEX and COMMENT ON statements of MySQL and Postgres are
+// applied in order, the other statements are ignored.
+package ddl
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	schema "github.com/ychengcloud/cre/loader/sql"
+)
+
+type inspect struct {
+	dialect string
+	files   []string
+}
+
+var _ schema.Inspector = (*inspect)(nil)
+
+// NewInspector returns an inspector which parses the DDL files in order.
+func NewInspector(dialect string, files ...string) schema.Inspector {
+	return &inspect{dialect: dialect, files: files}
+}
+
+// Inspect parses the DDL files, name 为空时使用脚本中指定的库名.
+func (i *inspect) Inspect(ctx context.Context, name string) (*schema.Schema, error) {
+	p, err := newParser(i.dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range i.files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("ddl: read file: %w", err)
+		}
+		if err := p.parse(file, string(b)); err != nil {
+			return nil, err
+		}
+	}
+	return p.result(name)
+}
+
+// Parse parses the DDL script and returns the schema.
+func Parse(dialect, name, src string) (*schema.Schema, error) {
+	p, err := newParser(dialect)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.parse("<script>", src); err != nil {
+		return nil, err
+	}
+	return p.result(name)
+}
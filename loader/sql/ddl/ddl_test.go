@@ -0,0 +1,381 @@
+package ddl
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ychengcloud/cre"
+	schema "github.com/ychengcloud/cre/loader/sql"
+	"github.com/ychengcloud/cre/spec"
+)
+
+func TestParseMySQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected func() *schema.Schema
+		wantErr  bool
+	}{
+		{
+			name: "no table",
+			src:  "CREATE DATABASE IF NOT EXISTS `test`; USE `test`; SET NAMES utf8mb4;",
+			expected: func() *schema.Schema {
+				return &schema.Schema{Name: "test"}
+			},
+		},
+		{
+			name: "columns",
+			src: "CREATE TABLE `users` (\n" +
+				"  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT 'id',\n" +
+				"  `name` varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL DEFAULT 'none',\n" +
+				"  `price` numeric(10,2) DEFAULT NULL,\n" +
+				"  `active` bool NOT NULL DEFAULT 1, -- 是否启用\n" +
+				"  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,\n" +
+				"  `name_len` int GENERATED ALWAYS AS (char_length(`name`)) VIRTUAL,\n" +
+				"  PRIMARY KEY (`id`)\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户';",
+			expected: func() *schema.Schema {
+				s := &schema.Schema{}
+				table := &schema.Table{Name: "users", Comment: "用户", Charset: "utf8mb4", Schema: s}
+				table.Columns = []*schema.Column{
+					{Name: "id", Type: &spec.IntegerType{Name: "bigint", Size: 64, Unsigned: true}, Comment: "id", Primary: true, AutoIncrement: true, Table: table},
+					{Name: "name", Type: &spec.StringType{Name: "varchar", Size: 64}, Charset: "utf8mb4", Collation: "utf8mb4_bin", Default: sql.NullString{String: "none", Valid: true}, Table: table},
+					{Name: "price", Type: &spec.FloatType{Name: "decimal", Precision: 10, Scale: 2}, Precision: 10, Scale: 2, Nullable: true, Table: table},
+					{Name: "active", Type: &spec.BoolType{Name: "tinyint"}, Default: sql.NullString{String: "1", Valid: true}, Table: table},
+					{Name: "updated_at", Type: &spec.TimeType{Name: "timestamp"}, Nullable: true, Default: sql.NullString{String: "CURRENT_TIMESTAMP", Valid: true}, OnUpdate: true, Table: table},
+					{Name: "name_len", Type: &spec.IntegerType{Name: "int", Size: 32}, Nullable: true, Generated: true, Table: table},
+				}
+				table.Indexes = []*schema.Index{
+					{Name: "PRIMARY", Unique: true, Primary: true, Type: "BTREE", IndexColumns: []*schema.IndexColumn{{SeqNo: 1, Column: "id"}}},
+				}
+				s.Tables = []*schema.Table{table}
+				return s
+			},
+		},
+		{
+			name: "indexes",
+			src: "CREATE TABLE `post` (`id` int PRIMARY KEY, `slug` varchar(100) UNIQUE, `title` varchar(100), `body` text," +
+				" UNIQUE KEY `uq_title` (`title`(20), `slug`), KEY (`title`), FULLTEXT INDEX `ft_body` (`body`) COMMENT 'search');" +
+				"CREATE INDEX `idx_title` USING HASH ON `post` (`title` DESC);",
+			expected: func() *schema.Schema {
+				s := &schema.Schema{}
+				table := &schema.Table{Name: "post", Schema: s}
+				table.Columns = []*schema.Column{
+					{Name: "id", Type: &spec.IntegerType{Name: "int", Size: 32}, Primary: true, Table: table},
+					{Name: "slug", Type: &spec.StringType{Name: "varchar", Size: 100}, Nullable: true, Table: table},
+					{Name: "title", Type: &spec.StringType{Name: "varchar", Size: 100}, Nullable: true, Table: table},
+					{Name: "body", Type: &spec.StringType{Name: "text"}, Nullable: true, Table: table},
+				}
+				table.Indexes = []*schema.Index{
+					{Name: "PRIMARY", Unique: true, Primary: true, Type: "BTREE", IndexColumns: []*schema.IndexColumn{{SeqNo: 1, Column: "id"}}},
+					{Name: "slug", Unique: true, Type: "BTREE", IndexColumns: []*schema.IndexColumn{{SeqNo: 1, Column: "slug"}}},
+					{Name: "uq_title", Unique: true, Type: "BTREE", IndexColumns: []*schema.IndexColumn{{SeqNo: 1, Column: "title", Sub: 20}, {SeqNo: 2, Column: "slug"}}},
+					{Name: "title", Type: "BTREE", IndexColumns: []*schema.IndexColumn{{SeqNo: 1, Column: "title"}}},
+					{Name: "ft_body", Type: "FULLTEXT", Comment: "search", IndexColumns: []*schema.IndexColumn{{SeqNo: 1, Column: "body"}}},
+					{Name: "idx_title", Type: "HASH", IndexColumns: []*schema.IndexColumn{{SeqNo: 1, Column: "title"}}},
+				}
+				s.Tables = []*schema.Table{table}
+				return s
+			},
+		},
+		{
+			name: "foreign keys",
+			src: "CREATE TABLE `user` (`id` int NOT NULL, PRIMARY KEY (`id`));" +
+				"CREATE TABLE `post` (`id` int NOT NULL, `uid` int, `pid` int, PRIMARY KEY (`id`)," +
+				" CONSTRAINT `fk_post_parent` FOREIGN KEY (`pid`) REFERENCES `post` (`id`) ON DELETE SET NULL);" +
+				"ALTER TABLE `post` ADD FOREIGN KEY (`uid`) REFERENCES `user` (`id`) ON DELETE CASCADE ON UPDATE RESTRICT;",
+			expected: func() *schema.Schema {
+				s := &schema.Schema{}
+				user := &schema.Table{Name: "user", Schema: s}
+				user.Columns = []*schema.Column{
+					{Name: "id", Type: &spec.IntegerType{Name: "int", Size: 32}, Primary: true, Table: user},
+				}
+				user.Indexes = []*schema.Index{
+					{Name: "PRIMARY", Unique: true, Primary: true, Type: "BTREE", IndexColumns: []*schema.IndexColumn{{SeqNo: 1, Column: "id"}}},
+				}
+				post := &schema.Table{Name: "post", Schema: s}
+				post.Columns = []*schema.Column{
+					{Name: "id", Type: &spec.IntegerType{Name: "int", Size: 32}, Primary: true, Table: post},
+					{Name: "uid", Type: &spec.IntegerType{Name: "int", Size: 32}, Nullable: true, Table: post},
+					{Name: "pid", Type: &spec.IntegerType{Name: "int", Size: 32}, Nullable: true, Table: post},
+				}
+				// MySQL 为外键列自动创建索引
+				post.Indexes = []*schema.Index{
+					{Name: "PRIMARY", Unique: true, Primary: true, Type: "BTREE", IndexColumns: []*schema.IndexColumn{{SeqNo: 1, Column: "id"}}},
+					{Name: "fk_post_parent", Type: "BTREE", IndexColumns: []*schema.IndexColumn{{SeqNo: 1, Column: "pid"}}},
+					{Name: "uid", Type: "BTREE", IndexColumns: []*schema.IndexColumn{{SeqNo: 1, Column: "uid"}}},
+				}
+				post.ForeignKeys = []*schema.ForeignKey{
+					{Name: "fk_post_parent", Table: post, Columns: post.Columns[2:3], RefTable: post, RefColumns: post.Columns[0:1], OnUpdate: schema.NoAction, OnDelete: schema.SetNull},
+					{Name: "post_ibfk_1", Table: post, Columns: post.Columns[1:2], RefTable: user, RefColumns: user.Columns[0:1], OnUpdate: schema.Restrict, OnDelete: schema.Cascade},
+				}
+				s.Tables = []*schema.Table{user, post}
+				return s
+			},
+		},
+		{
+			name: "alter table",
+			src: "CREATE TABLE `t` (`id` int, `a` int, `b` int, KEY `idx_a` (`a`));" +
+				"ALTER TABLE `t` ADD PRIMARY KEY (`id`), DROP COLUMN `a`, ADD COLUMN `c` varchar(10) NOT NULL, CHANGE `b` `d` bigint;" +
+				"ALTER TABLE `t` MODIFY `c` char COMMENT 'c';",
+			expected: func() *schema.Schema {
+				s := &schema.Schema{}
+				table := &schema.Table{Name: "t", Schema: s}
+				table.Columns = []*schema.Column{
+					{Name: "id", Type: &spec.IntegerType{Name: "int", Size: 32}, Primary: true, Table: table},
+					{Name: "d", Type: &spec.IntegerType{Name: "bigint", Size: 64}, Nullable: true, Table: table},
+					{Name: "c", Type: &spec.StringType{Name: "char", Size: 1}, Nullable: true, Comment: "c", Table: table},
+				}
+				table.Indexes = []*schema.Index{
+					{Name: "PRIMARY", Unique: true, Primary: true, Type: "BTREE", IndexColumns: []*schema.IndexColumn{{SeqNo: 1, Column: "id"}}},
+				}
+				s.Tables = []*schema.Table{table}
+				return s
+			},
+		},
+		{
+			name:    "ref table not found",
+			src:     "CREATE TABLE `t` (`id` int, `uid` int REFERENCES `user` (`id`));",
+			wantErr: true,
+		},
+		{
+			name:    "invalid type",
+			src:     "CREATE TABLE `t` (`id` integer_x);",
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := Parse(cre.MySQL, "", test.src)
+			require.Equal(t, test.wantErr, err != nil, err)
+			if test.wantErr {
+				return
+			}
+			require.EqualValues(t, test.expected(), s)
+		})
+	}
+}
+
+func TestParsePostgres(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected func() *schema.Schema
+		wantErr  bool
+	}{
+		{
+			name: "columns",
+			src: `CREATE TYPE public.mood AS ENUM ('sad', 'ok');
+CREATE TABLE IF NOT EXISTS public.Users (
+	id bigserial PRIMARY KEY,
+	"Name" varchar(64) NOT NULL DEFAULT 'none'::character varying,
+	price numeric(10, 2),
+	score double precision,
+	mood mood,
+	tags text[],
+	created_at timestamp(6) with time zone NOT NULL DEFAULT now(),
+	name_len integer GENERATED ALWAYS AS (length("Name")) STORED,
+	CONSTRAINT users_name_uniq UNIQUE ("Name")
+);
+COMMENT ON TABLE public.users IS 'users';
+COMMENT ON COLUMN public.users."Name" IS $$the user's name$$;`,
+			expected: func() *schema.Schema {
+				s := &schema.Schema{Name: "public"}
+				table := &schema.Table{Name: "users", Comment: "users", Namespace: "public", Schema: s}
+				table.Columns = []*schema.Column{
+					{Name: "id", Type: &spec.IntegerType{Name: "bigint", Size: 64}, Primary: true, AutoIncrement: true, Table: table},
+					{Name: "Name", Type: &spec.StringType{Name: "character varying", Size: 64}, Comment: "the user's name", Default: sql.NullString{String: "'none'::character varying", Valid: true}, Table: table},
+					{Name: "price", Type: &spec.FloatType{Name: "numeric", Precision: 10, Scale: 2}, Precision: 10, Scale: 2, Nullable: true, Table: table},
+					{Name: "score", Type: &spec.FloatType{Name: "double precision", Precision: 53}, Precision: 53, Nullable: true, Table: table},
+					{Name: "mood", Type: &spec.EnumType{Name: "mood", Values: []string{"sad", "ok"}}, Nullable: true, Table: table},
+					{Name: "tags", Type: &spec.SpatialType{Name: "array"}, Nullable: true, Table: table},
+					{Name: "created_at", Type: &spec.TimeType{Name: "timestamp with time zone"}, Default: sql.NullString{String: "now()", Valid: true}, Table: table},
+					{Name: "name_len", Type: &spec.IntegerType{Name: "integer", Size: 32}, Nullable: true, Generated: true, Table: table},
+				}
+				table.Indexes = []*schema.Index{
+					{Name: "users_pkey", Unique: true, Primary: true, Type: "btree", IndexColumns: []*schema.IndexColumn{{SeqNo: 1, Column: "id"}}},
+					{Name: "users_name_uniq", Unique: true, Type: "btree", IndexColumns: []*schema.IndexColumn{{SeqNo: 1, Column: "Name"}}},
+				}
+				s.Tables = []*schema.Table{table}
+				return s
+			},
+		},
+		{
+			name: "pg_dump",
+			src: `CREATE TABLE public.category (
+    id integer NOT NULL,
+    parent_id integer
+);
+CREATE SEQUENCE public.category_id_seq AS integer START WITH 1 INCREMENT BY 1;
+ALTER TABLE ONLY public.category ALTER COLUMN id SET DEFAULT nextval('public.category_id_seq'::regclass);
+ALTER TABLE ONLY public.category ADD CONSTRAINT category_pkey PRIMARY KEY (id);
+CREATE INDEX idx_parent ON public.category USING btree (parent_id);
+CREATE UNIQUE INDEX ON public.category (lower(parent_id::text));
+ALTER TABLE ONLY public.category
+    ADD CONSTRAINT category_parent_id_fkey FOREIGN KEY (parent_id) REFERENCES public.category(id) ON DELETE CASCADE;`,
+			expected: func() *schema.Schema {
+				s := &schema.Schema{Name: "public"}
+				table := &schema.Table{Name: "category", Namespace: "public", Schema: s}
+				table.Columns = []*schema.Column{
+					{Name: "id", Type: &spec.IntegerType{Name: "integer", Size: 32}, Primary: true, Default: sql.NullString{String: "nextval('public.category_id_seq'::regclass)", Valid: true}, Table: table},
+					{Name: "parent_id", Type: &spec.IntegerType{Name: "integer", Size: 32}, Nullable: true, Table: table},
+				}
+				table.Indexes = []*schema.Index{
+					{Name: "category_pkey", Unique: true, Primary: true, Type: "btree", IndexColumns: []*schema.IndexColumn{{SeqNo: 1, Column: "id"}}},
+					{Name: "idx_parent", Type: "btree", IndexColumns: []*schema.IndexColumn{{SeqNo: 1, Column: "parent_id"}}},
+					{Name: "category_expr_key", Unique: true, Type: "btree", IndexColumns: []*schema.IndexColumn{{SeqNo: 1, Expr: "lower(parent_id::text)"}}},
+				}
+				table.ForeignKeys = []*schema.ForeignKey{
+					{Name: "category_parent_id_fkey", Table: table, Columns: table.Columns[1:2], RefTable: table, RefColumns: table.Columns[0:1], OnUpdate: schema.NoAction, OnDelete: schema.Cascade},
+				}
+				s.Tables = []*schema.Table{table}
+				return s
+			},
+		},
+		{
+			// 不同 schema 中的同名表保留各自的 namespace，引用按限定名称解析
+			name: "schemas",
+			src: `CREATE TABLE auth.account (id int PRIMARY KEY);
+CREATE TABLE billing.account (id int PRIMARY KEY, owner_id int REFERENCES auth.account (id));
+CREATE TABLE invoice (account_id int REFERENCES billing.account, creator_id int);
+ALTER TABLE invoice ADD FOREIGN KEY (creator_id) REFERENCES public.invoice (account_id);
+COMMENT ON TABLE billing.account IS 'billing';`,
+			expected: func() *schema.Schema {
+				s := &schema.Schema{Name: "public"}
+				auth := &schema.Table{Name: "account", Namespace: "auth", Schema: s}
+				billing := &schema.Table{Name: "account", Namespace: "billing", Comment: "billing", Schema: s}
+				invoice := &schema.Table{Name: "invoice", Schema: s}
+				auth.Columns = []*schema.Column{
+					{Name: "id", Type: &spec.IntegerType{Name: "integer", Size: 32}, Primary: true, Table: auth},
+				}
+				billing.Columns = []*schema.Column{
+					{Name: "id", Type: &spec.IntegerType{Name: "integer", Size: 32}, Primary: true, Table: billing},
+					{Name: "owner_id", Type: &spec.IntegerType{Name: "integer", Size: 32}, Nullable: true, Table: billing},
+				}
+				invoice.Columns = []*schema.Column{
+					{Name: "account_id", Type: &spec.IntegerType{Name: "integer", Size: 32}, Nullable: true, Table: invoice},
+					{Name: "creator_id", Type: &spec.IntegerType{Name: "integer", Size: 32}, Nullable: true, Table: invoice},
+				}
+				auth.Indexes = []*schema.Index{
+					{Name: "account_pkey", Unique: true, Primary: true, Type: "btree", IndexColumns: []*schema.IndexColumn{{SeqNo: 1, Column: "id"}}},
+				}
+				billing.Indexes = []*schema.Index{
+					{Name: "account_pkey", Unique: true, Primary: true, Type: "btree", IndexColumns: []*schema.IndexColumn{{SeqNo: 1, Column: "id"}}},
+				}
+				billing.ForeignKeys = []*schema.ForeignKey{
+					{Name: "account_owner_id_fkey", Table: billing, Columns: billing.Columns[1:], RefTable: auth, RefColumns: auth.Columns, OnUpdate: schema.NoAction, OnDelete: schema.NoAction},
+				}
+				invoice.ForeignKeys = []*schema.ForeignKey{
+					{Name: "invoice_account_id_fkey", Table: invoice, Columns: invoice.Columns[:1], RefTable: billing, RefColumns: billing.Columns[:1], OnUpdate: schema.NoAction, OnDelete: schema.NoAction},
+					{Name: "invoice_creator_id_fkey", Table: invoice, Columns: invoice.Columns[1:], RefTable: invoice, RefColumns: invoice.Columns[:1], OnUpdate: schema.NoAction, OnDelete: schema.NoAction},
+				}
+				s.Tables = []*schema.Table{auth, billing, invoice}
+				return s
+			},
+		},
+		{
+			// 枚举和索引按 schema 区分，未知的扩展类型作为 user-defined 类型
+			name: "schema types",
+			src: `CREATE TYPE status AS ENUM ('new', 'done');
+CREATE TYPE billing.status AS ENUM ('open', 'paid');
+CREATE TABLE task (status status, name citext);
+CREATE TABLE billing.invoice (status status, area geometry(Point, 4326), task_status public.status);
+CREATE INDEX idx_status ON task (status);
+CREATE INDEX idx_status ON billing.invoice (status);
+DROP INDEX billing.idx_status;
+COMMENT ON TABLE billing.invoice IS E'it\'s\tpaid';`,
+			expected: func() *schema.Schema {
+				s := &schema.Schema{Name: "public"}
+				task := &schema.Table{Name: "task", Schema: s}
+				invoice := &schema.Table{Name: "invoice", Namespace: "billing", Comment: "it's\tpaid", Schema: s}
+				task.Columns = []*schema.Column{
+					{Name: "status", Type: &spec.EnumType{Name: "status", Values: []string{"new", "done"}}, Nullable: true, Table: task},
+					{Name: "name", Type: &spec.SpatialType{Name: "user-defined"}, Nullable: true, Table: task},
+				}
+				invoice.Columns = []*schema.Column{
+					{Name: "status", Type: &spec.EnumType{Name: "status", Values: []string{"open", "paid"}}, Nullable: true, Table: invoice},
+					{Name: "area", Type: &spec.SpatialType{Name: "user-defined"}, Nullable: true, Table: invoice},
+					{Name: "task_status", Type: &spec.EnumType{Name: "status", Values: []string{"new", "done"}}, Nullable: true, Table: invoice},
+				}
+				task.Indexes = []*schema.Index{
+					{Name: "idx_status", Type: "btree", IndexColumns: []*schema.IndexColumn{{SeqNo: 1, Column: "status"}}},
+				}
+				invoice.Indexes = []*schema.Index{}
+				s.Tables = []*schema.Table{task, invoice}
+				return s
+			},
+		},
+		{
+			name:    "column not found",
+			src:     `CREATE TABLE t (id int); COMMENT ON COLUMN t.name IS 'name';`,
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := Parse(cre.Postgres, "public", test.src)
+			require.Equal(t, test.wantErr, err != nil, err)
+			if test.wantErr {
+				return
+			}
+			require.EqualValues(t, test.expected(), s)
+		})
+	}
+}
+
+func TestInspect(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{filepath.Join(dir, "001_init.sql"), filepath.Join(dir, "002_post.sql")}
+	require.NoError(t, os.WriteFile(files[0], []byte("CREATE TABLE `user` (`id` int PRIMARY KEY, `name` text);"), 0644))
+	require.NoError(t, os.WriteFile(files[1], []byte("CREATE TABLE `post` (`id` int PRIMARY KEY, `uid` int, FOREIGN KEY (`uid`) REFERENCES `user` (`id`));"), 0644))
+
+	s, err := NewInspector(cre.MySQL, files...).Inspect(context.Background(), "blog")
+	require.NoError(t, err)
+	require.Equal(t, "blog", s.Name)
+	require.Len(t, s.Tables, 2)
+	require.Len(t, s.Table("post").ForeignKeys, 1)
+	require.Equal(t, "user", s.Table("post").ForeignKeys[0].RefTable.Name)
+
+	_, err = NewInspector(cre.SQLite, files...).Inspect(context.Background(), "main")
+	require.Error(t, err)
+
+	_, err = NewInspector(cre.MySQL, filepath.Join(dir, "none.sql")).Inspect(context.Background(), "blog")
+	require.Error(t, err)
+}
+
+func TestPrimaryKeyOrder(t *testing.T) {
+	s, err := Parse(cre.Postgres, "public", "CREATE TABLE org (b text, a text, PRIMARY KEY (a, b));")
+	require.NoError(t, err)
+	converted, err := s.Convert()
+	require.NoError(t, err)
+
+	org := converted.Table("org")
+	require.Equal(t, []*spec.Field{org.GetField("a"), org.GetField("b")}, org.PrimaryKey)
+}
+
+func TestParseFixtures(t *testing.T) {
+	tests := []struct {
+		dialect string
+		file    string
+		tables  int
+	}{
+		{dialect: cre.MySQL, file: "mysql.sql", tables: 6},
+		{dialect: cre.MySQL, file: "blog_mysql.sql", tables: 8},
+		{dialect: cre.Postgres, file: "postgres.sql", tables: 8},
+	}
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			s, err := NewInspector(test.dialect, filepath.Join("..", "..", "..", "test", "sql", test.file)).Inspect(context.Background(), "")
+			require.NoError(t, err)
+			require.Len(t, s.Tables, test.tables)
+
+			_, err = s.Convert()
+			require.NoError(t, err)
+		})
+	}
+}
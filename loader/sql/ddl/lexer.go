@@ -0,0 +1,268 @@
+package ddl
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ychengcloud/cre"
+)
+
+type tokenKind int
+
+const (
+	tkIdent  tokenKind = iota // bare word, identifier or keyword
+	tkQuoted                  // quoted identifier: `name` or "name"
+	tkString                  // string literal: 'value'
+	tkNumber                  // numeric literal
+	tkPunct                   // single punctuation character
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// is reports whether the token is one of the given keywords (case insensitive).
+// Quoted identifiers are never keywords.
+func (t token) is(keywords ...string) bool {
+	if t.kind != tkIdent {
+		return false
+	}
+	for _, kw := range keywords {
+		if strings.EqualFold(t.text, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPunct reports whether the token is the given punctuation character.
+func (t token) isPunct(p string) bool {
+	return t.kind == tkPunct && t.text == p
+}
+
+// raw returns the token as it appears in a column type or an expression.
+func (t token) raw() string {
+	switch t.kind {
+	case tkString:
+		return "'" + strings.ReplaceAll(t.text, "'", "''") + "'"
+	case tkQuoted:
+		return `"` + t.text + `"`
+	default:
+		return t.text
+	}
+}
+
+// lexer splits DDL scripts into statements of tokens.
+type lexer struct {
+	dialect string
+	src     string
+	pos     int
+}
+
+// statements returns the tokens of all statements in src, split by ';'.
+// Comments and empty statements are dropped.
+func statements(dialect, src string) ([][]token, error) {
+	l := &lexer{dialect: dialect, src: src}
+
+	var (
+		stmts [][]token
+		stmt  []token
+	)
+	for {
+		tok, ok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		if tok.isPunct(";") {
+			if len(stmt) > 0 {
+				stmts = append(stmts, stmt)
+			}
+			stmt = nil
+			continue
+		}
+		stmt = append(stmt, tok)
+	}
+	if len(stmt) > 0 {
+		stmts = append(stmts, stmt)
+	}
+	return stmts, nil
+}
+
+func (l *lexer) peekByte(offset int) byte {
+	if l.pos+offset < len(l.src) {
+		return l.src[l.pos+offset]
+	}
+	return 0
+}
+
+func (l *lexer) skipSpaceAndComments() error {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			l.pos++
+		case c == '-' && l.peekByte(1) == '-', c == '#' && l.dialect == cre.MySQL:
+			end := strings.IndexByte(l.src[l.pos:], '\n')
+			if end == -1 {
+				l.pos = len(l.src)
+			} else {
+				l.pos += end + 1
+			}
+		case c == '/' && l.peekByte(1) == '*':
+			end := strings.Index(l.src[l.pos+2:], "*/")
+			if end == -1 {
+				return fmt.Errorf("unterminated comment at offset %d", l.pos)
+			}
+			l.pos += end + 4
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func (l *lexer) next() (token, bool, error) {
+	if err := l.skipSpaceAndComments(); err != nil {
+		return token{}, false, err
+	}
+	if l.pos >= len(l.src) {
+		return token{}, false, nil
+	}
+
+	start := l.pos
+	c := l.src[l.pos]
+	switch {
+	case c == '\'':
+		s, err := l.quoted('\'', l.dialect == cre.MySQL)
+		return token{kind: tkString, text: s}, true, err
+	case (c == 'E' || c == 'e') && l.peekByte(1) == '\'' && l.dialect == cre.Postgres:
+		// Postgres 转义字符串: E'it\'s'
+		l.pos++
+		s, err := l.quoted('\'', true)
+		return token{kind: tkString, text: s}, true, err
+	case c == '`':
+		s, err := l.quoted('`', false)
+		return token{kind: tkQuoted, text: s}, true, err
+	case c == '"':
+		s, err := l.quoted('"', false)
+		// MySQL 默认模式下双引号为字符串
+		if l.dialect == cre.MySQL {
+			return token{kind: tkString, text: s}, true, err
+		}
+		return token{kind: tkQuoted, text: s}, true, err
+	case c == '$' && l.dialect == cre.Postgres:
+		if s, ok := l.dollarQuoted(); ok {
+			return token{kind: tkString, text: s}, true, nil
+		}
+		l.pos++
+		return token{kind: tkPunct, text: "$"}, true, nil
+	case c >= '0' && c <= '9', c == '.' && l.peekByte(1) >= '0' && l.peekByte(1) <= '9':
+		for l.pos < len(l.src) {
+			c := l.src[l.pos]
+			if (c >= '0' && c <= '9') || c == '.' || c == 'e' || c == 'E' {
+				l.pos++
+				continue
+			}
+			break
+		}
+		return token{kind: tkNumber, text: l.src[start:l.pos]}, true, nil
+	}
+
+	r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+	if isIdentStart(r) {
+		l.pos += size
+		for l.pos < len(l.src) {
+			r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+			if !isIdentPart(r) {
+				break
+			}
+			l.pos += size
+		}
+		return token{kind: tkIdent, text: l.src[start:l.pos]}, true, nil
+	}
+
+	// 运算符 :: 作为一个整体，便于还原表达式
+	if c == ':' && l.peekByte(1) == ':' {
+		l.pos += 2
+		return token{kind: tkPunct, text: "::"}, true, nil
+	}
+	l.pos += size
+	return token{kind: tkPunct, text: l.src[start:l.pos]}, true, nil
+}
+
+// quoted scans a quoted string, the quote character is escaped by doubling it.
+func (l *lexer) quoted(quote byte, backslash bool) (string, error) {
+	start := l.pos
+	l.pos++
+
+	var b strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case backslash && c == '\\' && l.pos+1 < len(l.src):
+			b.WriteByte(unescape(l.src[l.pos+1]))
+			l.pos += 2
+		case c == quote && l.peekByte(1) == quote:
+			b.WriteByte(quote)
+			l.pos += 2
+		case c == quote:
+			l.pos++
+			return b.String(), nil
+		default:
+			b.WriteByte(c)
+			l.pos++
+		}
+	}
+	return "", fmt.Errorf("unterminated quoted string at offset %d", start)
+}
+
+func unescape(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	case '0':
+		return 0
+	default:
+		return c
+	}
+}
+
+// dollarQuoted scans a postgres dollar quoted string: $tag$ ... $tag$.
+func (l *lexer) dollarQuoted() (string, bool) {
+	end := strings.IndexByte(l.src[l.pos+1:], '$')
+	if end == -1 {
+		return "", false
+	}
+	tag := l.src[l.pos : l.pos+end+2]
+	for _, r := range tag[1 : len(tag)-1] {
+		if !isIdentPart(r) {
+			return "", false
+		}
+	}
+
+	body := l.src[l.pos+len(tag):]
+	close := strings.Index(body, tag)
+	if close == -1 {
+		return "", false
+	}
+	l.pos += len(tag) + close + len(tag)
+	return body[:close], true
+}
@@ -0,0 +1,1270 @@
+package ddl
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ychengcloud/cre"
+	schema "github.com/ychengcloud/cre/loader/sql"
+	"github.com/ychengcloud/cre/spec"
+)
+
+// parser builds the schema from DDL statements, statements are applied in order.
+type parser struct {
+	dialect string
+	schema  *schema.Schema
+
+	// MySQL: USE / CREATE DATABASE 指定的库名
+	database string
+	// Postgres: CREATE TYPE ... AS ENUM 定义的枚举类型
+	enums map[string][]string
+	// 外键在所有语句解析完成后再关联引用表
+	fks []*foreignKey
+}
+
+// foreignKey is a foreign key definition whose references are resolved lazily.
+type foreignKey struct {
+	name       string
+	named      bool
+	table      *schema.Table
+	columns    []string
+	refSchema  string // 引用表的 schema 限定名称，未限定时为空
+	refTable   string
+	refColumns []string
+	onUpdate   schema.ReferenceOption
+	onDelete   schema.ReferenceOption
+}
+
+func newParser(dialect string) (*parser, error) {
+	switch dialect {
+	case cre.MySQL, cre.Postgres:
+	default:
+		return nil, fmt.Errorf("ddl: unsupported dialect: %v", dialect)
+	}
+	return &parser{
+		dialect: dialect,
+		schema:  &schema.Schema{},
+		enums:   make(map[string][]string),
+	}, nil
+}
+
+// parse applies all the statements of the script.
+func (p *parser) parse(file, src string) error {
+	stmts, err := statements(p.dialect, src)
+	if err != nil {
+		return fmt.Errorf("ddl: %s: %w", file, err)
+	}
+	for n, stmt := range stmts {
+		if err := p.statement(newCursor(stmt)); err != nil {
+			return fmt.Errorf("ddl: %s: statement %d: %w", file, n+1, err)
+		}
+	}
+	return nil
+}
+
+// result resolves the foreign keys and returns the schema.
+// name 为空时使用脚本中 USE / CREATE DATABASE 指定的库名
+func (p *parser) result(name string) (*schema.Schema, error) {
+	if name == "" {
+		name = p.database
+	}
+	p.schema.Name = name
+
+	for _, fk := range p.fks {
+		if err := p.resolve(fk); err != nil {
+			return nil, err
+		}
+	}
+	return p.schema, nil
+}
+
+func (p *parser) statement(c *cursor) error {
+	switch {
+	case c.accept("CREATE"):
+		return p.create(c)
+	case c.accept("ALTER", "TABLE"):
+		return p.alterTable(c)
+	case c.accept("DROP", "TABLE"):
+		return p.dropTable(c)
+	case c.accept("DROP", "INDEX"):
+		return p.dropIndex(c)
+	case c.accept("COMMENT", "ON"):
+		return p.comment(c)
+	case c.accept("USE"):
+		if p.dialect == cre.MySQL && c.isName() {
+			p.database = p.name(c.next())
+		}
+	}
+	// 其他语句 (INSERT, SET, GRANT ...) 与结构无关，直接忽略
+	return nil
+}
+
+func (p *parser) create(c *cursor) error {
+	c.accept("OR", "REPLACE")
+	for c.peek().is("TEMPORARY", "TEMP", "UNLOGGED", "GLOBAL", "LOCAL") {
+		c.next()
+	}
+
+	switch {
+	case c.accept("TABLE"):
+		return p.createTable(c)
+	case c.accept("INDEX"):
+		return p.createIndex(c, false, "")
+	case c.accept("UNIQUE", "INDEX"):
+		return p.createIndex(c, true, "")
+	case c.peek().is("FULLTEXT", "SPATIAL") && c.peekN(1).is("INDEX"):
+		typ := strings.ToUpper(c.next().text)
+		c.next()
+		return p.createIndex(c, false, typ)
+	case c.accept("TYPE"):
+		return p.createType(c)
+	case c.accept("DATABASE"), c.accept("SCHEMA"):
+		c.accept("IF", "NOT", "EXISTS")
+		if p.dialect == cre.MySQL && p.database == "" && c.isName() {
+			p.database = p.name(c.next())
+		}
+	}
+	return nil
+}
+
+// name returns the identifier of the token.
+// Postgres 未加引号的标识符统一转为小写
+func (p *parser) name(t token) string {
+	if t.kind == tkIdent && p.dialect == cre.Postgres {
+		return strings.ToLower(t.text)
+	}
+	return t.text
+}
+
+// qualifiedName parses a dotted name and returns its parts, eg: public.users.
+func (p *parser) qualifiedName(c *cursor) ([]string, error) {
+	var parts []string
+	for {
+		if !c.isName() {
+			return nil, fmt.Errorf("expect identifier but got %q", c.peek().text)
+		}
+		parts = append(parts, p.name(c.next()))
+		if !c.acceptPunct(".") {
+			return parts, nil
+		}
+	}
+}
+
+// tableName parses the table name, Postgres 的 schema 限定名称作为 namespace 返回，MySQL 的库名忽略
+func (p *parser) tableName(c *cursor) (namespace, name string, err error) {
+	parts, err := p.qualifiedName(c)
+	if err != nil {
+		return "", "", err
+	}
+	return p.splitName(parts)
+}
+
+// splitName returns the namespace and the name of the qualified table name.
+func (p *parser) splitName(parts []string) (namespace, name string, err error) {
+	switch {
+	case len(parts) == 0:
+		return "", "", fmt.Errorf("empty table name")
+	case len(parts) > 1 && p.dialect == cre.Postgres:
+		return parts[len(parts)-2], parts[len(parts)-1], nil
+	}
+	return "", parts[len(parts)-1], nil
+}
+
+// lookup returns the table by the namespace and the name, 不存在时返回 nil
+// Postgres 中未限定的名称与 public 中的表相同; 未限定且只有一个同名的表时，返回该表
+func (p *parser) lookup(namespace, name string) *schema.Table {
+	var (
+		found *schema.Table
+		n     int
+	)
+	for _, t := range p.schema.Tables {
+		if t.Name != name {
+			continue
+		}
+		if t.Namespace == namespace {
+			return t
+		}
+		if p.dialect == cre.Postgres && defaultSchema(t.Namespace) && defaultSchema(namespace) {
+			return t
+		}
+		found = t
+		n++
+	}
+	if namespace == "" && n == 1 {
+		return found
+	}
+	return nil
+}
+
+func defaultSchema(namespace string) bool {
+	return namespace == "" || namespace == "public"
+}
+
+// sameSchema reports whether the namespaces are the same, 未限定的名称与 public 相同
+func sameSchema(a, b string) bool {
+	return a == b || (defaultSchema(a) && defaultSchema(b))
+}
+
+// enumKey returns the key of the enum type in p.enums, public 中的类型使用未限定的名称
+func enumKey(namespace, name string) string {
+	if defaultSchema(namespace) {
+		return name
+	}
+	return spec.QualifiedName(namespace, name)
+}
+
+func (p *parser) table(namespace, name string) (*schema.Table, error) {
+	t := p.lookup(namespace, name)
+	if t == nil {
+		return nil, fmt.Errorf("table %q not found", spec.QualifiedName(namespace, name))
+	}
+	return t, nil
+}
+
+func (p *parser) createTable(c *cursor) error {
+	c.accept("IF", "NOT", "EXISTS")
+	namespace, name, err := p.tableName(c)
+	if err != nil {
+		return err
+	}
+	// CREATE TABLE ... LIKE / AS SELECT / PARTITION OF 无法从脚本中得到列定义
+	if !c.peek().isPunct("(") {
+		return nil
+	}
+	body, err := c.group()
+	if err != nil {
+		return err
+	}
+
+	t := &schema.Table{
+		Name:      name,
+		Namespace: namespace,
+		Schema:    p.schema,
+	}
+
+	// 先解析列，再解析表级约束
+	var defs [][]token
+	for _, def := range split(body) {
+		if len(def) > 0 {
+			defs = append(defs, def)
+		}
+	}
+	for _, def := range defs {
+		if isConstraint(def[0]) {
+			continue
+		}
+		column, err := p.column(t, newCursor(def))
+		if err != nil {
+			return err
+		}
+		t.Columns = append(t.Columns, column)
+	}
+	for _, def := range defs {
+		if !isConstraint(def[0]) {
+			continue
+		}
+		if err := p.constraint(t, newCursor(def)); err != nil {
+			return err
+		}
+	}
+
+	p.tableOptions(t, c)
+
+	p.removeTable(namespace, name)
+	p.schema.Tables = append(p.schema.Tables, t)
+	return nil
+}
+
+// tableOptions parses the MySQL table options, eg: ENGINE=InnoDB COMMENT='users'.
+func (p *parser) tableOptions(t *schema.Table, c *cursor) {
+	for !c.done() {
+		switch {
+		case c.accept("COMMENT"):
+			c.acceptPunct("=")
+			t.Comment = c.next().text
+		case c.accept("CHARACTER", "SET"), c.accept("CHARSET"):
+			c.acceptPunct("=")
+			t.Charset = c.next().text
+		case c.accept("COLLATE"):
+			c.acceptPunct("=")
+			t.Collation = c.next().text
+		case c.accept("AUTO_INCREMENT"):
+			c.acceptPunct("=")
+			t.AutoIncrement, _ = strconv.Atoi(c.next().text)
+		default:
+			c.next()
+		}
+	}
+}
+
+func (p *parser) removeTable(namespace, name string) {
+	rt := p.lookup(namespace, name)
+	for i, t := range p.schema.Tables {
+		if t != rt {
+			continue
+		}
+		p.schema.Tables = append(p.schema.Tables[:i], p.schema.Tables[i+1:]...)
+
+		fks := p.fks[:0]
+		for _, fk := range p.fks {
+			if fk.table != t {
+				fks = append(fks, fk)
+			}
+		}
+		p.fks = fks
+		return
+	}
+}
+
+// isConstraint reports whether the table element is a constraint or an index.
+func isConstraint(t token) bool {
+	return t.is("CONSTRAINT", "PRIMARY", "UNIQUE", "KEY", "INDEX", "FULLTEXT", "SPATIAL", "FOREIGN", "CHECK", "EXCLUDE", "LIKE")
+}
+
+// isColumnAttr reports whether the token starts a column attribute,
+// which ends the column type or the default expression.
+func isColumnAttr(c *cursor) bool {
+	t := c.peek()
+	if t.is("CHARACTER") {
+		return c.peekN(1).is("SET")
+	}
+	if t.is("ON") {
+		return c.peekN(1).is("UPDATE")
+	}
+	return t.is("NOT", "NULL", "DEFAULT", "PRIMARY", "KEY", "UNIQUE", "AUTO_INCREMENT", "AUTOINCREMENT",
+		"COMMENT", "REFERENCES", "CHECK", "CONSTRAINT", "COLLATE", "CHARSET", "GENERATED", "AS",
+		"VISIBLE", "INVISIBLE", "SRID", "STORAGE", "COLUMN_FORMAT", "ENGINE_ATTRIBUTE")
+}
+
+// expression consumes tokens until the next column attribute.
+func expression(c *cursor) ([]token, error) {
+	start := c.pos
+	for !c.done() && !isColumnAttr(c) {
+		if c.peek().isPunct("(") {
+			if _, err := c.group(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		c.next()
+	}
+	return c.toks[start:c.pos], nil
+}
+
+// column parses the column definition, the inline constraints are added to the table.
+func (p *parser) column(t *schema.Table, c *cursor) (*schema.Column, error) {
+	if !c.isName() {
+		return nil, fmt.Errorf("expect column name but got %q", c.peek().text)
+	}
+	column := &schema.Column{
+		Name:     p.name(c.next()),
+		Nullable: true,
+		Table:    t,
+	}
+
+	typ, err := expression(c)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.columnType(column, typ); err != nil {
+		return nil, fmt.Errorf("column %s.%s: %w", t.Name, column.Name, err)
+	}
+
+	var constraint string
+	for !c.done() {
+		switch {
+		case c.accept("NOT", "NULL"):
+			column.Nullable = false
+		case c.accept("NULL"):
+			column.Nullable = true
+		case c.accept("DEFAULT"):
+			expr, err := expression(c)
+			if err != nil {
+				return nil, err
+			}
+			column.Default = defaultValue(expr)
+		case c.accept("PRIMARY", "KEY"), c.accept("KEY"):
+			p.addPrimaryKey(t, constraint, []*schema.IndexColumn{{SeqNo: 1, Column: column.Name}})
+			column.Primary = true
+			column.Nullable = false
+		case c.accept("UNIQUE"):
+			c.accept("KEY")
+			p.addIndex(t, &schema.Index{
+				Name:         constraint,
+				Unique:       true,
+				IndexColumns: []*schema.IndexColumn{{SeqNo: 1, Column: column.Name}},
+			})
+		case c.accept("AUTO_INCREMENT"), c.accept("AUTOINCREMENT"):
+			column.AutoIncrement = true
+		case c.accept("COMMENT"):
+			column.Comment = c.next().text
+		case c.accept("ON", "UPDATE"):
+			column.OnUpdate = true
+			c.next()
+			if c.peek().isPunct("(") {
+				if _, err := c.group(); err != nil {
+					return nil, err
+				}
+			}
+		case c.accept("REFERENCES"):
+			fk, err := p.references(c)
+			if err != nil {
+				return nil, err
+			}
+			fk.columns = []string{column.Name}
+			p.addForeignKey(t, constraint, fk)
+		case c.accept("CHECK"):
+			if _, err := c.group(); err != nil {
+				return nil, err
+			}
+		case c.accept("CONSTRAINT"):
+			if c.isName() {
+				constraint = p.name(c.next())
+			}
+			continue
+		case c.accept("COLLATE"):
+			column.Collation = p.name(c.next())
+		case c.accept("CHARACTER", "SET"), c.accept("CHARSET"):
+			column.Charset = p.name(c.next())
+		case c.accept("GENERATED"):
+			// GENERATED { ALWAYS | BY DEFAULT } AS IDENTITY [ ( sequence_options ) ]
+			// GENERATED ALWAYS AS ( generation_expr ) STORED
+			c.accept("ALWAYS")
+			c.accept("BY", "DEFAULT")
+			c.accept("AS")
+			if c.accept("IDENTITY") {
+				column.AutoIncrement = true
+				column.Nullable = false
+			} else {
+				column.Generated = true
+			}
+			if c.peek().isPunct("(") {
+				if _, err := c.group(); err != nil {
+					return nil, err
+				}
+			}
+		case c.accept("AS"):
+			// MySQL generated column: AS (expr) [VIRTUAL | STORED]
+			column.Generated = true
+			if _, err := c.group(); err != nil {
+				return nil, err
+			}
+		default:
+			c.next()
+		}
+		constraint = ""
+	}
+	return column, nil
+}
+
+// defaultValue returns the column default of the expression.
+// 字符串字面量只保留其值，与 information_schema 中 MySQL 的默认值一致
+func defaultValue(expr []token) sql.NullString {
+	if len(expr) == 1 {
+		switch {
+		case expr[0].kind == tkString:
+			return sql.NullString{String: expr[0].text, Valid: true}
+		case expr[0].is("NULL"):
+			return sql.NullString{}
+		}
+	}
+	if len(expr) == 0 {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: rawText(expr), Valid: true}
+}
+
+// constraint parses the table constraint or the index definition.
+func (p *parser) constraint(t *schema.Table, c *cursor) error {
+	var name string
+	if c.accept("CONSTRAINT") && c.isName() && !c.peek().is("PRIMARY", "UNIQUE", "FOREIGN", "CHECK", "EXCLUDE") {
+		name = p.name(c.next())
+	}
+
+	switch {
+	case c.accept("PRIMARY", "KEY"):
+		_, _, columns, err := p.index(c)
+		if err != nil {
+			return err
+		}
+		p.addPrimaryKey(t, name, columns)
+	case c.accept("UNIQUE"):
+		if !c.accept("KEY") {
+			c.accept("INDEX")
+		}
+		return p.tableIndex(t, c, name, true, "")
+	case c.accept("KEY"), c.accept("INDEX"):
+		return p.tableIndex(t, c, name, false, "")
+	case c.peek().is("FULLTEXT", "SPATIAL"):
+		typ := strings.ToUpper(c.next().text)
+		if !c.accept("KEY") {
+			c.accept("INDEX")
+		}
+		return p.tableIndex(t, c, name, false, typ)
+	case c.accept("FOREIGN", "KEY"):
+		// MySQL: FOREIGN KEY [index_name] (col, ...)
+		if c.isName() {
+			c.next()
+		}
+		columns, err := p.nameList(c)
+		if err != nil {
+			return err
+		}
+		if !c.accept("REFERENCES") {
+			return fmt.Errorf("expect REFERENCES but got %q", c.peek().text)
+		}
+		fk, err := p.references(c)
+		if err != nil {
+			return err
+		}
+		fk.columns = columns
+		p.addForeignKey(t, name, fk)
+	}
+	// CHECK, EXCLUDE, LIKE 等约束忽略
+	return nil
+}
+
+func (p *parser) tableIndex(t *schema.Table, c *cursor, name string, unique bool, typ string) error {
+	idxName, idxType, columns, err := p.index(c)
+	if err != nil {
+		return err
+	}
+	if idxName != "" {
+		name = idxName
+	}
+	if typ == "" {
+		typ = idxType
+	}
+	p.addIndex(t, &schema.Index{
+		Name:         name,
+		Unique:       unique,
+		Type:         typ,
+		Comment:      indexComment(c),
+		IndexColumns: columns,
+	})
+	return nil
+}
+
+// index parses [index_name] [USING type] (key_part, ...) [USING type].
+func (p *parser) index(c *cursor) (name, typ string, columns []*schema.IndexColumn, err error) {
+	if c.isName() && !c.peek().is("USING") {
+		name = p.name(c.next())
+	}
+	if c.accept("USING") {
+		typ = c.next().text
+	}
+	columns, err = p.indexColumns(c)
+	if err != nil {
+		return
+	}
+	if c.accept("USING") {
+		typ = c.next().text
+	}
+	return
+}
+
+// indexComment parses the MySQL index option: COMMENT 'string'.
+func indexComment(c *cursor) string {
+	for !c.done() {
+		if c.accept("COMMENT") {
+			return c.next().text
+		}
+		c.next()
+	}
+	return ""
+}
+
+// indexColumns parses the key parts of the index.
+// 列名后跟随的前缀长度记为 Sub, 表达式索引记为 Expr
+func (p *parser) indexColumns(c *cursor) ([]*schema.IndexColumn, error) {
+	body, err := c.group()
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []*schema.IndexColumn
+	for i, part := range split(body) {
+		ic := &schema.IndexColumn{SeqNo: i + 1}
+		pc := newCursor(part)
+		switch {
+		case pc.isName() && pc.peekN(1).isPunct("(") && pc.peekN(2).kind == tkNumber && pc.peekN(3).isPunct(")"):
+			ic.Column = p.name(pc.next())
+			ic.Sub, _ = strconv.Atoi(pc.peekN(1).text)
+		case pc.isName() && !pc.peekN(1).isPunct("("):
+			ic.Column = p.name(pc.next())
+		default:
+			ic.Expr = rawText(part)
+		}
+		columns = append(columns, ic)
+	}
+	return columns, nil
+}
+
+// nameList parses a parenthesized list of identifiers.
+func (p *parser) nameList(c *cursor) ([]string, error) {
+	body, err := c.group()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, part := range split(body) {
+		if len(part) == 0 {
+			return nil, fmt.Errorf("empty column name")
+		}
+		names = append(names, p.name(part[0]))
+	}
+	return names, nil
+}
+
+// references parses REFERENCES tbl [(col, ...)] [ON DELETE action] [ON UPDATE action].
+func (p *parser) references(c *cursor) (*foreignKey, error) {
+	refSchema, refTable, err := p.tableName(c)
+	if err != nil {
+		return nil, err
+	}
+	fk := &foreignKey{
+		refSchema: refSchema,
+		refTable:  refTable,
+		onUpdate:  schema.NoAction,
+		onDelete:  schema.NoAction,
+	}
+	if c.peek().isPunct("(") {
+		if fk.refColumns, err = p.nameList(c); err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		switch {
+		case c.accept("ON", "DELETE"):
+			fk.onDelete = referenceOption(c)
+		case c.accept("ON", "UPDATE"):
+			fk.onUpdate = referenceOption(c)
+		case c.accept("MATCH"):
+			c.next()
+		case c.accept("NOT", "DEFERRABLE"), c.accept("DEFERRABLE"):
+		case c.accept("INITIALLY"):
+			c.next()
+		default:
+			return fk, nil
+		}
+	}
+}
+
+func referenceOption(c *cursor) schema.ReferenceOption {
+	var option schema.ReferenceOption
+	switch {
+	case c.accept("CASCADE"):
+		option = schema.Cascade
+	case c.accept("RESTRICT"):
+		option = schema.Restrict
+	case c.accept("SET", "NULL"):
+		option = schema.SetNull
+	case c.accept("SET", "DEFAULT"):
+		option = schema.SetDefault
+	default:
+		c.accept("NO", "ACTION")
+		option = schema.NoAction
+	}
+	// Postgres 15: SET NULL (column, ...)
+	if c.peek().isPunct("(") {
+		c.group()
+	}
+	return option
+}
+
+// addPrimaryKey adds the primary key index and marks the columns as primary.
+// 主键索引名称: MySQL 为 PRIMARY, Postgres 为 <table>_pkey
+func (p *parser) addPrimaryKey(t *schema.Table, name string, columns []*schema.IndexColumn) {
+	switch {
+	case p.dialect == cre.MySQL:
+		name = "PRIMARY"
+	case name == "":
+		name = t.Name + "_pkey"
+	}
+	for _, ic := range columns {
+		if column := t.Column(ic.Column); column != nil {
+			column.Primary = true
+			column.Nullable = false
+		}
+	}
+	p.addIndex(t, &schema.Index{
+		Name:         name,
+		Unique:       true,
+		Primary:      true,
+		IndexColumns: columns,
+	})
+}
+
+// addIndex adds the index to the table, the index name and type are filled with
+// the default values of the dialect if they are missing.
+func (p *parser) addIndex(t *schema.Table, index *schema.Index) {
+	if index.Name == "" {
+		index.Name = p.indexName(t, index)
+	}
+	switch {
+	case index.Type == "" && p.dialect == cre.MySQL:
+		index.Type = "BTREE"
+	case index.Type == "":
+		index.Type = "btree"
+	case p.dialect == cre.MySQL:
+		index.Type = strings.ToUpper(index.Type)
+	default:
+		index.Type = strings.ToLower(index.Type)
+	}
+	t.Indexes = append(t.Indexes, index)
+}
+
+// indexName generates the index name as the database does.
+// MySQL: 第一列列名，重复时追加 _2, _3 ...
+// Postgres: <table>_<columns>_key (唯一约束) 或 <table>_<columns>_idx
+func (p *parser) indexName(t *schema.Table, index *schema.Index) string {
+	var columns []string
+	for _, ic := range index.IndexColumns {
+		if ic.Column == "" {
+			columns = append(columns, "expr")
+			continue
+		}
+		columns = append(columns, ic.Column)
+	}
+
+	var base string
+	if p.dialect == cre.MySQL {
+		base = columns[0]
+	} else {
+		suffix := "idx"
+		if index.Unique {
+			suffix = "key"
+		}
+		base = t.Name + "_" + strings.Join(columns, "_") + "_" + suffix
+	}
+
+	name := base
+	for n := 1; findIndex(t, name) != -1; n++ {
+		if p.dialect == cre.MySQL {
+			name = fmt.Sprintf("%s_%d", base, n+1)
+		} else {
+			name = fmt.Sprintf("%s%d", base, n)
+		}
+	}
+	return name
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func findIndex(t *schema.Table, name string) int {
+	for i, index := range t.Indexes {
+		if index.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// addForeignKey adds the foreign key to be resolved.
+// 外键名称: MySQL 为 <table>_ibfk_<n>, Postgres 为 <table>_<columns>_fkey
+func (p *parser) addForeignKey(t *schema.Table, name string, fk *foreignKey) {
+	fk.table = t
+	fk.name = name
+	fk.named = name != ""
+	if !fk.named {
+		if p.dialect == cre.MySQL {
+			n := 1
+			for _, f := range p.fks {
+				if f.table == t && !f.named {
+					n++
+				}
+			}
+			fk.name = fmt.Sprintf("%s_ibfk_%d", t.Name, n)
+		} else {
+			fk.name = t.Name + "_" + strings.Join(fk.columns, "_") + "_fkey"
+		}
+	}
+	p.fks = append(p.fks, fk)
+}
+
+// resolve resolves the columns of the foreign key.
+// 未指定引用列时引用父表的主键; MySQL 会为外键列自动创建索引
+func (p *parser) resolve(fk *foreignKey) error {
+	t := fk.table
+	rt := p.lookup(fk.refSchema, fk.refTable)
+	if rt == nil {
+		return fmt.Errorf("ddl/fks: ref table %q not found for fk %q", spec.QualifiedName(fk.refSchema, fk.refTable), fk.name)
+	}
+
+	foreignKey := &schema.ForeignKey{
+		Name:     fk.name,
+		Table:    t,
+		RefTable: rt,
+		OnUpdate: fk.onUpdate,
+		OnDelete: fk.onDelete,
+	}
+	for _, name := range fk.columns {
+		c := t.Column(name)
+		if c == nil {
+			return fmt.Errorf("ddl/fks: column %q not found for fk %q", name, fk.name)
+		}
+		foreignKey.Columns = append(foreignKey.Columns, c)
+	}
+
+	refColumns := fk.refColumns
+	if len(refColumns) == 0 {
+		for _, c := range rt.Columns {
+			if c.Primary {
+				refColumns = append(refColumns, c.Name)
+			}
+		}
+	}
+	for _, name := range refColumns {
+		c := rt.Column(name)
+		if c == nil {
+			return fmt.Errorf("ddl/fks: ref column %q not found for fk %q", name, fk.name)
+		}
+		foreignKey.RefColumns = append(foreignKey.RefColumns, c)
+	}
+	if len(foreignKey.Columns) != len(foreignKey.RefColumns) {
+		return fmt.Errorf("ddl/fks: columns mismatch for fk %q", fk.name)
+	}
+	t.ForeignKeys = append(t.ForeignKeys, foreignKey)
+
+	if p.dialect == cre.MySQL && !hasLeadingIndex(t, fk.columns) {
+		var columns []*schema.IndexColumn
+		for i, name := range fk.columns {
+			columns = append(columns, &schema.IndexColumn{SeqNo: i + 1, Column: name})
+		}
+		index := &schema.Index{IndexColumns: columns}
+		if fk.named {
+			index.Name = fk.name
+		}
+		p.addIndex(t, index)
+	}
+	return nil
+}
+
+// hasLeadingIndex reports whether an index of the table starts with the columns.
+func hasLeadingIndex(t *schema.Table, columns []string) bool {
+	for _, index := range t.Indexes {
+		if len(index.IndexColumns) < len(columns) {
+			continue
+		}
+		match := true
+		for i, name := range columns {
+			if index.IndexColumns[i].Column != name {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// createIndex parses:
+// Postgres: CREATE [UNIQUE] INDEX [CONCURRENTLY] [[IF NOT EXISTS] name] ON [ONLY] tbl [USING method] (key_part, ...)
+// MySQL: CREATE [UNIQUE | FULLTEXT | SPATIAL] INDEX name [USING type] ON tbl (key_part, ...)
+func (p *parser) createIndex(c *cursor, unique bool, typ string) error {
+	c.accept("CONCURRENTLY")
+	c.accept("IF", "NOT", "EXISTS")
+
+	var name string
+	if !c.peek().is("ON") {
+		parts, err := p.qualifiedName(c)
+		if err != nil {
+			return err
+		}
+		name = parts[len(parts)-1]
+	}
+	if c.accept("USING") {
+		typ = c.next().text
+	}
+	if !c.accept("ON") {
+		return fmt.Errorf("expect ON but got %q", c.peek().text)
+	}
+	c.accept("ONLY")
+	namespace, tableName, err := p.tableName(c)
+	if err != nil {
+		return err
+	}
+	t, err := p.table(namespace, tableName)
+	if err != nil {
+		return err
+	}
+
+	if c.accept("USING") {
+		typ = c.next().text
+	}
+	columns, err := p.indexColumns(c)
+	if err != nil {
+		return err
+	}
+	p.addIndex(t, &schema.Index{
+		Name:         name,
+		Unique:       unique,
+		Type:         typ,
+		Comment:      indexComment(c),
+		IndexColumns: columns,
+	})
+	return nil
+}
+
+// createType parses the Postgres enum type: CREATE TYPE name AS ENUM ('a', 'b').
+func (p *parser) createType(c *cursor) error {
+	parts, err := p.qualifiedName(c)
+	if err != nil {
+		return err
+	}
+	if !c.accept("AS", "ENUM") {
+		return nil
+	}
+	body, err := c.group()
+	if err != nil {
+		return err
+	}
+	var values []string
+	for _, part := range split(body) {
+		if len(part) > 0 {
+			values = append(values, part[0].text)
+		}
+	}
+	namespace, name, err := p.splitName(parts)
+	if err != nil {
+		return err
+	}
+	p.enums[enumKey(namespace, name)] = values
+	return nil
+}
+
+// alterTable applies the actions of ALTER TABLE.
+func (p *parser) alterTable(c *cursor) error {
+	c.accept("IF", "EXISTS")
+	c.accept("ONLY")
+	namespace, name, err := p.tableName(c)
+	if err != nil {
+		return err
+	}
+	t, err := p.table(namespace, name)
+	if err != nil {
+		return err
+	}
+
+	for _, action := range split(c.rest()) {
+		if err := p.alterAction(t, newCursor(action)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *parser) alterAction(t *schema.Table, c *cursor) error {
+	switch {
+	case c.accept("ADD"):
+		if !c.accept("COLUMN") && isConstraint(c.peek()) {
+			return p.constraint(t, c)
+		}
+		c.accept("IF", "NOT", "EXISTS")
+		column, err := p.column(t, c)
+		if err != nil {
+			return err
+		}
+		t.Columns = append(t.Columns, column)
+	case c.accept("DROP"):
+		return p.dropFromTable(t, c)
+	case c.accept("MODIFY"):
+		// MySQL: MODIFY [COLUMN] col def
+		c.accept("COLUMN")
+		column, err := p.column(t, c)
+		if err != nil {
+			return err
+		}
+		return p.replaceColumn(t, column.Name, column)
+	case c.accept("CHANGE"):
+		// MySQL: CHANGE [COLUMN] old new def
+		c.accept("COLUMN")
+		old := p.name(c.next())
+		column, err := p.column(t, c)
+		if err != nil {
+			return err
+		}
+		return p.replaceColumn(t, old, column)
+	case c.accept("ALTER"):
+		c.accept("COLUMN")
+		column := t.Column(p.name(c.peek()))
+		if column == nil {
+			return fmt.Errorf("column %q not found in table %q", c.peek().text, t.Name)
+		}
+		c.next()
+		return p.alterColumn(column, c)
+	case c.accept("RENAME"):
+		if c.accept("TO") || c.accept("AS") {
+			p.renameTable(t, p.name(c.next()))
+			return nil
+		}
+		c.accept("COLUMN")
+		old := p.name(c.next())
+		c.accept("TO")
+		p.renameColumn(t, old, p.name(c.next()))
+	}
+	// OWNER TO, ENGINE=..., SET ... 等与结构无关的操作忽略
+	return nil
+}
+
+// alterColumn parses the Postgres ALTER COLUMN actions.
+func (p *parser) alterColumn(column *schema.Column, c *cursor) error {
+	switch {
+	case c.accept("SET", "DEFAULT"):
+		column.Default = defaultValue(c.rest())
+	case c.accept("DROP", "DEFAULT"):
+		column.Default = sql.NullString{}
+	case c.accept("SET", "NOT", "NULL"):
+		column.Nullable = false
+	case c.accept("DROP", "NOT", "NULL"):
+		column.Nullable = true
+	case c.accept("ADD", "GENERATED"):
+		column.AutoIncrement = true
+		column.Nullable = false
+	case c.accept("SET", "DATA", "TYPE"), c.accept("TYPE"):
+		typ, err := expression(c)
+		if err != nil {
+			return err
+		}
+		return p.columnType(column, typ)
+	}
+	return nil
+}
+
+// dropFromTable parses the DROP actions of ALTER TABLE.
+func (p *parser) dropFromTable(t *schema.Table, c *cursor) error {
+	switch {
+	case c.accept("PRIMARY", "KEY"):
+		for _, index := range t.Indexes {
+			if index.Primary {
+				p.dropConstraint(t, index.Name)
+			}
+		}
+	case c.accept("INDEX"), c.accept("KEY"), c.accept("FOREIGN", "KEY"), c.accept("CONSTRAINT"), c.accept("CHECK"):
+		c.accept("IF", "EXISTS")
+		p.dropConstraint(t, p.name(c.next()))
+	default:
+		c.accept("COLUMN")
+		c.accept("IF", "EXISTS")
+		name := p.name(c.next())
+		dropColumn(t, name)
+
+		fks := p.fks[:0]
+		for _, fk := range p.fks {
+			if fk.table != t || !containsName(fk.columns, name) {
+				fks = append(fks, fk)
+			}
+		}
+		p.fks = fks
+	}
+	return nil
+}
+
+// dropConstraint removes the index or the foreign key by name.
+func (p *parser) dropConstraint(t *schema.Table, name string) {
+	if i := findIndex(t, name); i != -1 {
+		if t.Indexes[i].Primary {
+			for _, ic := range t.Indexes[i].IndexColumns {
+				if column := t.Column(ic.Column); column != nil {
+					column.Primary = false
+				}
+			}
+		}
+		t.Indexes = append(t.Indexes[:i], t.Indexes[i+1:]...)
+	}
+	for i, fk := range p.fks {
+		if fk.table == t && fk.name == name {
+			p.fks = append(p.fks[:i], p.fks[i+1:]...)
+			return
+		}
+	}
+}
+
+func (p *parser) replaceColumn(t *schema.Table, old string, column *schema.Column) error {
+	for i, c := range t.Columns {
+		if c.Name == old {
+			column.Primary = column.Primary || c.Primary
+			t.Columns[i] = column
+			p.renameColumn(t, old, column.Name)
+			return nil
+		}
+	}
+	return fmt.Errorf("column %q not found in table %q", old, t.Name)
+}
+
+// renameColumn renames the column and the references of it.
+func (p *parser) renameColumn(t *schema.Table, old, name string) {
+	if column := t.Column(old); column != nil {
+		column.Name = name
+	}
+	for _, index := range t.Indexes {
+		for _, ic := range index.IndexColumns {
+			if ic.Column == old {
+				ic.Column = name
+			}
+		}
+	}
+	for _, fk := range p.fks {
+		if fk.table == t {
+			replaceName(fk.columns, old, name)
+		}
+		if p.lookup(fk.refSchema, fk.refTable) == t {
+			replaceName(fk.refColumns, old, name)
+		}
+	}
+}
+
+func (p *parser) renameTable(t *schema.Table, name string) {
+	for _, fk := range p.fks {
+		if p.lookup(fk.refSchema, fk.refTable) == t {
+			fk.refTable = name
+		}
+	}
+	t.Name = name
+}
+
+func replaceName(names []string, old, name string) {
+	for i := range names {
+		if names[i] == old {
+			names[i] = name
+		}
+	}
+}
+
+// dropColumn removes the column and its index parts, the empty indexes are removed too.
+func dropColumn(t *schema.Table, name string) {
+	for i, c := range t.Columns {
+		if c.Name == name {
+			t.Columns = append(t.Columns[:i], t.Columns[i+1:]...)
+			break
+		}
+	}
+
+	indexes := t.Indexes[:0]
+	for _, index := range t.Indexes {
+		columns := index.IndexColumns[:0]
+		for _, ic := range index.IndexColumns {
+			if ic.Column != name {
+				columns = append(columns, ic)
+			}
+		}
+		index.IndexColumns = columns
+		if len(columns) > 0 {
+			indexes = append(indexes, index)
+		}
+	}
+	t.Indexes = indexes
+}
+
+// dropTable parses DROP TABLE [IF EXISTS] tbl, ...
+func (p *parser) dropTable(c *cursor) error {
+	c.accept("IF", "EXISTS")
+	for _, part := range split(c.rest()) {
+		pc := newCursor(part)
+		namespace, name, err := p.tableName(pc)
+		if err != nil {
+			return err
+		}
+		p.removeTable(namespace, name)
+	}
+	return nil
+}
+
+// dropIndex parses DROP INDEX [IF EXISTS] name [ON tbl].
+func (p *parser) dropIndex(c *cursor) error {
+	c.accept("CONCURRENTLY")
+	c.accept("IF", "EXISTS")
+	parts, err := p.qualifiedName(c)
+	if err != nil {
+		return err
+	}
+	namespace, name, err := p.splitName(parts)
+	if err != nil {
+		return err
+	}
+	tables := p.schema.Tables
+	// MySQL: DROP INDEX name ON tbl
+	if c.accept("ON") {
+		tableNS, tableName, err := p.tableName(c)
+		if err != nil {
+			return err
+		}
+		t, err := p.table(tableNS, tableName)
+		if err != nil {
+			return err
+		}
+		tables = []*schema.Table{t}
+	}
+	for _, t := range tables {
+		// Postgres 的索引与表在同一个 schema 中
+		if p.dialect == cre.Postgres && !sameSchema(t.Namespace, namespace) {
+			continue
+		}
+		if i := findIndex(t, name); i != -1 {
+			t.Indexes = append(t.Indexes[:i], t.Indexes[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// comment parses COMMENT ON { TABLE tbl | COLUMN tbl.col } IS 'text'.
+func (p *parser) comment(c *cursor) error {
+	var isColumn bool
+	switch {
+	case c.accept("TABLE"):
+	case c.accept("COLUMN"):
+		isColumn = true
+	default:
+		return nil
+	}
+	parts, err := p.qualifiedName(c)
+	if err != nil {
+		return err
+	}
+	if !c.accept("IS") {
+		return fmt.Errorf("expect IS but got %q", c.peek().text)
+	}
+	var text string
+	if t := c.next(); t.kind == tkString {
+		text = t.text
+	}
+
+	tableParts := parts
+	if isColumn {
+		if len(parts) < 2 {
+			return fmt.Errorf("invalid column name %q", strings.Join(parts, "."))
+		}
+		tableParts = parts[:len(parts)-1]
+	}
+	namespace, name, err := p.splitName(tableParts)
+	if err != nil {
+		return err
+	}
+	t, err := p.table(namespace, name)
+	if err != nil {
+		return err
+	}
+	if !isColumn {
+		t.Comment = text
+		return nil
+	}
+	column := t.Column(parts[len(parts)-1])
+	if column == nil {
+		return fmt.Errorf("column %q not found in table %q", parts[len(parts)-1], t.Name)
+	}
+	column.Comment = text
+	return nil
+}
@@ -0,0 +1,108 @@
+package ddl
+
+import (
+	"strings"
+
+	"github.com/ychengcloud/cre"
+	schema "github.com/ychengcloud/cre/loader/sql"
+	"github.com/ychengcloud/cre/loader/sql/mysql"
+	"github.com/ychengcloud/cre/loader/sql/postgres"
+	"github.com/ychengcloud/cre/spec"
+)
+
+// columnType parses the column type tokens with the parser of the dialect.
+func (p *parser) columnType(column *schema.Column, typ []token) error {
+	var (
+		ct  spec.Type
+		err error
+	)
+	switch p.dialect {
+	case cre.MySQL:
+		ct, err = mysql.ParseType(mysqlColumnType(rawText(typ)))
+	case cre.Postgres:
+		ct, err = p.postgresType(column, typ)
+	}
+	if err != nil {
+		return err
+	}
+
+	column.Type = ct
+	switch ct := ct.(type) {
+	case *spec.FloatType:
+		column.Precision = ct.Precision
+		column.Scale = ct.Scale
+	}
+	return nil
+}
+
+// mysqlColumnType rewrites the type written in DDL into the column_type reported
+// by information_schema, eg: integer => int, bool => tinyint(1), numeric => decimal(10,0).
+func mysqlColumnType(colDef string) string {
+	colDef = strings.ToLower(strings.TrimSpace(colDef))
+	idx := strings.IndexAny(colDef, "( ")
+	if idx == -1 {
+		idx = len(colDef)
+	}
+	name, rest := colDef[:idx], colDef[idx:]
+
+	switch name {
+	case "bool", "boolean":
+		return "tinyint(1)"
+	case "integer":
+		name = mysql.TypeInt
+	case "numeric", "dec", "fixed":
+		name = mysql.TypeDecimal
+	case "real":
+		name = mysql.TypeDouble
+	case "character":
+		name = mysql.TypeChar
+		if strings.HasPrefix(rest, " varying") {
+			name, rest = mysql.TypeVarchar, strings.TrimPrefix(rest, " varying")
+		}
+	}
+
+	// 未指定长度时的默认值
+	if !strings.HasPrefix(rest, "(") {
+		switch name {
+		case mysql.TypeDecimal:
+			rest = "(10,0)" + rest
+		case mysql.TypeChar, mysql.TypeBinary, mysql.TypeBit:
+			rest = "(1)" + rest
+		}
+	}
+	return name + rest
+}
+
+// postgresType parses the postgres column type, the enum types created by
+// CREATE TYPE and the serial types are resolved here.
+func (p *parser) postgresType(column *schema.Column, typ []token) (spec.Type, error) {
+	// 枚举类型可能带有 schema 前缀，eg: public.mood; 未限定时在列所在表的 schema 和 public 中查找
+	if n := len(typ); n == 1 || (n == 3 && typ[1].isPunct(".")) {
+		name := p.name(typ[n-1])
+		namespaces := []string{""}
+		if n == 3 {
+			namespaces = []string{p.name(typ[0])}
+		} else if column.Table != nil {
+			namespaces = []string{column.Table.Namespace, ""}
+		}
+		for _, ns := range namespaces {
+			if values, ok := p.enums[enumKey(ns, name)]; ok {
+				return &spec.EnumType{Name: name, Values: append([]string(nil), values...)}, nil
+			}
+		}
+	}
+
+	colDef := rawText(typ)
+	switch strings.ToLower(colDef) {
+	case postgres.TypeSmallSerial, postgres.TypeSerial, postgres.TypeBigSerial,
+		postgres.TypeSerial2, postgres.TypeSerial4, postgres.TypeSerial8:
+		column.AutoIncrement = true
+		column.Nullable = false
+	}
+	ct, err := postgres.ParseType(colDef)
+	if err != nil {
+		// citext、domain、PostGIS geometry 等扩展或自定义类型，与 inspect 一致作为 user-defined 类型
+		return &spec.SpatialType{Name: postgres.TypeUserDefined}, nil
+	}
+	return ct, nil
+}
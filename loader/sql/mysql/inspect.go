@@ -17,6 +17,8 @@ type inspect struct {
 	Driver cre.Driver
 
 	schema *schema.Schema
+	// tableMap 按表名索引，批量查询的结果按表分组
+	tableMap map[string]*schema.Table
 
 	// Database information
 	version string
@@ -42,7 +44,11 @@ func (i *inspect) Inspect(ctx context.Context, name string) (*schema.Schema, err
 		return nil, err
 	}
 	i.schema.Tables = tables
+	if len(tables) == 0 {
+		return i.schema, nil
+	}
 
+	// 列、索引和外键均为 schema 级别的批量查询，查询次数与表的数量无关
 	err = i.inspectColumns(ctx)
 	if err != nil {
 		return nil, err
@@ -107,6 +113,7 @@ func (i *inspect) tables(ctx context.Context) ([]*schema.Table, error) {
 	defer rows.Close()
 
 	var tables []*schema.Table
+	i.tableMap = make(map[string]*schema.Table)
 	for rows.Next() {
 		if err := rows.Scan(&name, &charset, &collation, &autoIncrement, &comment, &options); err != nil {
 			if err == sql.ErrNoRows {
@@ -125,6 +132,7 @@ func (i *inspect) tables(ctx context.Context) ([]*schema.Table, error) {
 			Options:       options.String,
 		}
 		tables = append(tables, table)
+		i.tableMap[name] = table
 
 	}
 
@@ -135,54 +143,25 @@ func (i *inspect) tables(ctx context.Context) ([]*schema.Table, error) {
 	return tables, nil
 }
 
+// inspectColumns 一次查询 schema 中所有表的列，按表名分组
 func (i *inspect) inspectColumns(ctx context.Context) error {
-	for _, table := range i.schema.Tables {
-		columns, err := i.columns(ctx, table)
-		if err != nil {
-			return err
-		}
-		table.Columns = columns
-	}
-	return nil
-}
-
-func (i *inspect) inspectIndexes(ctx context.Context) error {
-	for _, table := range i.schema.Tables {
-		indexes, err := i.indexes(ctx, table.Name)
-		if err != nil {
-			return err
-		}
-		table.Indexes = indexes
-	}
-	return nil
-}
-
-func (i *inspect) inspectForeignKeys(ctx context.Context) error {
-	for _, table := range i.schema.Tables {
-		foreignKeys, err := i.foreignKeys(ctx, table)
-		if err != nil {
-			return err
-		}
-		table.ForeignKeys = foreignKeys
-	}
-	return nil
-}
-
-func (i *inspect) columns(ctx context.Context, t *schema.Table) ([]*schema.Column, error) {
-	args := []any{i.schema.Name, t.Name}
-
-	rows, err := i.querySqlRows(ctx, ColumnsQuery, args...)
+	rows, err := i.querySqlRows(ctx, ColumnsQuery, i.schema.Name)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	var columns []*schema.Column
 	for rows.Next() {
-		var name, colType, comment, nullable, key, defaults, extra, charset, collation sql.NullString
+		var table, name, colType, comment, nullable, key, defaults, extra, charset, collation sql.NullString
 		var precision, scale sql.NullInt64
-		if err := rows.Scan(&name, &colType, &comment, &nullable, &key, &defaults, &extra, &charset, &collation, &precision, &scale); err != nil {
-			return nil, err
+		if err := rows.Scan(&table, &name, &colType, &comment, &nullable, &key, &defaults, &extra, &charset, &collation, &precision, &scale); err != nil {
+			return err
+		}
+
+		// 视图等未加载的表
+		t, ok := i.tableMap[table.String]
+		if !ok {
+			continue
 		}
 
 		column := &schema.Column{
@@ -193,7 +172,7 @@ func (i *inspect) columns(ctx context.Context, t *schema.Table) ([]*schema.Colum
 		if colType.Valid {
 			ct, err := ParseType(colType.String)
 			if err != nil {
-				return nil, err
+				return err
 			}
 			column.Type = ct
 		}
@@ -232,45 +211,45 @@ func (i *inspect) columns(ctx context.Context, t *schema.Table) ([]*schema.Colum
 			column.Scale = int(scale.Int64)
 
 		}
-		columns = append(columns, column)
+		t.Columns = append(t.Columns, column)
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return columns, nil
+	return rows.Err()
 }
 
-func (i *inspect) indexes(ctx context.Context, name string) ([]*schema.Index, error) {
-	args := []any{i.schema.Name, name}
-
+// inspectIndexes 一次查询 schema 中所有表的索引，按表名分组
+func (i *inspect) inspectIndexes(ctx context.Context) error {
 	query := IndexesQuery
 	if i.indexExpr() {
 		query = IndexesExprQuery
 	}
 
-	rows, err := i.querySqlRows(ctx, query, args...)
+	rows, err := i.querySqlRows(ctx, query, i.schema.Name)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	var indexs []*schema.Index
-	indexMap := make(map[string]*schema.Index)
+	// 索引名称仅在表内唯一
+	indexMap := make(map[[2]string]*schema.Index)
 	for rows.Next() {
 		var (
 			nonunique                      bool
 			seqno                          int
-			name, indexType                string
+			table, name, indexType         string
 			subPart                        sql.NullInt64
 			column, expr, comment, collate sql.NullString
 		)
-		if err := rows.Scan(&name, &column, &nonunique, &seqno, &indexType, &collate, &comment, &subPart, &expr); err != nil {
-			return nil, fmt.Errorf("mysql/indexes: scanning index: %w", err)
+		if err := rows.Scan(&table, &name, &column, &nonunique, &seqno, &indexType, &collate, &comment, &subPart, &expr); err != nil {
+			return fmt.Errorf("mysql/indexes: scanning index: %w", err)
+		}
+
+		t, ok := i.tableMap[table]
+		if !ok {
+			continue
 		}
 
-		index, ok := indexMap[name]
+		index, ok := indexMap[[2]string{table, name}]
 		if !ok {
 			index = &schema.Index{
 				Name:    name,
@@ -282,8 +261,8 @@ func (i *inspect) indexes(ctx context.Context, name string) ([]*schema.Index, er
 				index.Primary = true
 			}
 
-			indexMap[name] = index
-			indexs = append(indexs, index)
+			indexMap[[2]string{table, name}] = index
+			t.Indexes = append(t.Indexes, index)
 		}
 
 		part := &schema.IndexColumn{
@@ -294,79 +273,76 @@ func (i *inspect) indexes(ctx context.Context, name string) ([]*schema.Index, er
 		if subPart.Int64 > 0 {
 			part.Sub = int(subPart.Int64)
 		}
+		// 函数索引(8.0.13+)没有列名，仅有表达式
+		if expr.Valid && expr.String != "" {
+			part.Expr = expr.String
+		}
 		index.IndexColumns = append(index.IndexColumns, part)
 
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return indexs, nil
+	return rows.Err()
 }
 
-func (i *inspect) foreignKeys(ctx context.Context, t *schema.Table) ([]*schema.ForeignKey, error) {
-	args := []any{i.schema.Name, t.Name}
-
-	query := ForeignKeysQuery
-
-	rows, err := i.querySqlRows(ctx, query, args...)
+// inspectForeignKeys 一次查询 schema 中所有表的外键，所有表的列加载完成后才能解析引用
+func (i *inspect) inspectForeignKeys(ctx context.Context) error {
+	rows, err := i.querySqlRows(ctx, ForeignKeysQuery, i.schema.Name)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	var foreignKeys []*schema.ForeignKey
-	foreignKeysMap := make(map[string]*schema.ForeignKey)
+	foreignKeysMap := make(map[[2]string]*schema.ForeignKey)
 	for rows.Next() {
 		var (
 			name, table, column, tableSchema, refTable, refColumn, refSchema, updateRule, deleteRule string
 		)
 		if err := rows.Scan(&name, &table, &column, &tableSchema, &refTable, &refColumn, &refSchema, &updateRule, &deleteRule); err != nil {
-			return nil, fmt.Errorf("mysql/fks: scanning fk: %w", err)
+			return fmt.Errorf("mysql/fks: scanning fk: %w", err)
 		}
 
-		foreignKey, ok := foreignKeysMap[name]
-		rt := t.Schema.Table(refTable)
+		t, ok := i.tableMap[table]
+		if !ok {
+			continue
+		}
+
+		foreignKey, ok := foreignKeysMap[[2]string{table, name}]
+		//目前只支持引用同一个数据库的外键
+		rt := i.tableMap[refTable]
 		if rt == nil {
 
-			return nil, fmt.Errorf("mysql/fks: ref table %q not found for fk %q", refTable, name)
+			return fmt.Errorf("mysql/fks: ref table %q not found for fk %q", refTable, name)
 		}
 
 		if !ok {
 			foreignKey = &schema.ForeignKey{
-				Name:  name,
-				Table: t,
-				//目前只支持引用同一个数据库的外键
+				Name:     name,
+				Table:    t,
 				RefTable: rt,
 				OnUpdate: schema.ReferenceOption(updateRule),
 				OnDelete: schema.ReferenceOption(deleteRule),
 			}
 
-			foreignKeysMap[name] = foreignKey
-			foreignKeys = append(foreignKeys, foreignKey)
+			foreignKeysMap[[2]string{table, name}] = foreignKey
+			t.ForeignKeys = append(t.ForeignKeys, foreignKey)
 		}
 
 		c := t.Column(column)
 		if c == nil {
-			return nil, fmt.Errorf("mysql/fks: column %q not found for fk %q", column, foreignKey.Name)
+			return fmt.Errorf("mysql/fks: column %q not found for fk %q", column, foreignKey.Name)
 
 		}
 		foreignKey.Columns = append(foreignKey.Columns, c)
 
 		rc := foreignKey.RefTable.Column(refColumn)
 		if rc == nil {
-			return nil, fmt.Errorf("mysql/fks: ref column %q not found for fk %q", refColumn, foreignKey.Name)
+			return fmt.Errorf("mysql/fks: ref column %q not found for fk %q", refColumn, foreignKey.Name)
 		}
 		foreignKey.RefColumns = append(foreignKey.RefColumns, rc)
 
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return foreignKeys, nil
+	return rows.Err()
 }
 
 func (i *inspect) querySqlRows(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"context"
+	"fmt"
 	"regexp"
 	"strings"
 	"testing"
@@ -25,6 +26,76 @@ func (m mysqlMock) info() {
 
 }
 
+// tables mocks n tables, 每个表的 parent_id 引用前一个表
+func (m mysqlMock) tables(n int) {
+	m.info()
+
+	tables := sqlmock.NewRows(TablesQueryFields)
+	columns := sqlmock.NewRows(ColumnsQueryFields)
+	indexes := sqlmock.NewRows(IndexesQueryFields)
+	fks := sqlmock.NewRows(ForeignKeysQueryFields)
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("t%d", i)
+		tables.AddRow(name, "utf8mb4", "utf8mb4_0900_ai_ci", nil, "", "")
+		columns.AddRow(name, "id", "bigint(20)", "", "NO", "PRI", nil, "auto_increment", "", "", nil, nil).
+			AddRow(name, "name", "varchar(255)", "", "YES", "", nil, "", "utf8mb4", "utf8mb4_bin", nil, nil).
+			AddRow(name, "parent_id", "bigint(20)", "", "YES", "MUL", nil, "", "", "", nil, nil)
+		indexes.AddRow(name, "PRIMARY", "id", "0", "1", "BTREE", "A", "", nil, nil)
+		if i > 0 {
+			fks.AddRow(name+"_parent", name, "parent_id", "test", fmt.Sprintf("t%d", i-1), "id", "test", "NO ACTION", "CASCADE")
+		}
+	}
+
+	m.ExpectQuery(Escape(TablesQuery)).WithArgs("test").WillReturnRows(tables)
+	m.ExpectQuery(Escape(ColumnsQuery)).WithArgs("test").WillReturnRows(columns)
+	m.ExpectQuery(Escape(IndexesExprQuery)).WithArgs("test").WillReturnRows(indexes)
+	m.ExpectQuery(Escape(ForeignKeysQuery)).WithArgs("test").WillReturnRows(fks)
+}
+
+// countDriver counts the queries sent to the database.
+type countDriver struct {
+	cre.Driver
+	queries int
+}
+
+func (d *countDriver) Query(ctx context.Context, query string, args ...any) (any, error) {
+	d.queries++
+	return d.Driver.Query(ctx, query, args...)
+}
+
+func (d *countDriver) QueryRow(ctx context.Context, query string, args ...any) (any, error) {
+	d.queries++
+	return d.Driver.QueryRow(ctx, query, args...)
+}
+
+// BenchmarkInspect 查询次数与表的数量无关，各规模下 queries/op 保持不变
+func BenchmarkInspect(b *testing.B) {
+	for _, n := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("tables=%d", n), func(b *testing.B) {
+			var queries int
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				db, mock, err := sqlmock.New()
+				require.NoError(b, err)
+				mysqlMock{mock}.tables(n)
+				drv := &countDriver{Driver: schema.OpenDB(cre.MySQL, db)}
+				b.StartTimer()
+
+				s, err := (&inspect{Driver: drv}).Inspect(context.Background(), "test")
+
+				b.StopTimer()
+				require.NoError(b, err)
+				require.Len(b, s.Tables, n)
+				require.NoError(b, mock.ExpectationsWereMet())
+				queries = drv.queries
+				db.Close()
+				b.StartTimer()
+			}
+			b.ReportMetric(float64(queries), "queries/op")
+		})
+	}
+}
+
 func TestTables(t *testing.T) {
 
 	tests := []struct {
@@ -60,37 +131,38 @@ func TestTables(t *testing.T) {
 					WillReturnRows(sqlmock.NewRows(TablesQueryFields).
 						AddRow("table", "utf8mb4", "utf8mb4_0900_ai_ci", nil, "Comment", "COMPRESSION=ZLIB"))
 
-				//column_name, column_type, column_comment, is_nullable, column_key, column_default, extra, character_set_name, collation_name, numeric_precision, numeric_scale
+				//table_name, column_name, column_type, column_comment, is_nullable, column_key, column_default, extra, character_set_name, collation_name, numeric_precision, numeric_scale
 				mock.ExpectQuery(Escape(ColumnsQuery)).
-					WithArgs("test", "table").
+					WithArgs("test").
 					WillReturnRows(sqlmock.NewRows(ColumnsQueryFields).
-						AddRow("bigint", "bigint(20)", "中文bigint comment", "NO", "PRI", nil, "auto_increment", "", "", nil, nil).
-						AddRow("varchar", "varchar(255)", "varchar comment", "YES", "YES", nil, "", "", "", nil, nil).
-						AddRow("varchar1", "varchar(255) character set utf8mb4_bin collate utf8mb4_bin", "varchar1 comment", "YES", "YES", nil, "", "", "", nil, nil).
-						AddRow("longtext", "longtext", "longtext comment", "YES", "YES", nil, "", "", "", nil, nil).
-						AddRow("char", "char(36)", "char comment", "YES", "YES", nil, "", "", "utf8mb4_bin", nil, nil).
-						AddRow("decimal", "decimal(6, 4)", "decimal comment", "NO", "YES", nil, "", "", "", "6", "4").
-						AddRow("datetime", "datetime(5)", "datetime comment", "NO", "YES", nil, "", "", "", nil, nil).
-						AddRow("point", "point", "point comment", "NO", "YES", nil, "", "", "", nil, nil).
-						AddRow("json", "json", "json comment", "NO", "YES", nil, "", "", "", nil, nil).
-						AddRow("enum", "enum('a','b','c')", "enum comment", "NO", "YES", nil, "", "", "", nil, nil))
-
-				//INDEX_NAME, COLUMN_NAME, NON_UNIQUE, SEQ_IN_INDEX, INDEX_TYPE, COLLATION, INDEX_COMMENT, SUB_PART, EXPRESSION
-				f := append(IndexesQueryFields)
+						AddRow("table", "bigint", "bigint(20)", "中文bigint comment", "NO", "PRI", nil, "auto_increment", "", "", nil, nil).
+						AddRow("table", "varchar", "varchar(255)", "varchar comment", "YES", "YES", nil, "", "", "", nil, nil).
+						AddRow("table", "varchar1", "varchar(255) character set utf8mb4_bin collate utf8mb4_bin", "varchar1 comment", "YES", "YES", nil, "", "", "", nil, nil).
+						AddRow("table", "longtext", "longtext", "longtext comment", "YES", "YES", nil, "", "", "", nil, nil).
+						AddRow("table", "char", "char(36)", "char comment", "YES", "YES", nil, "", "", "utf8mb4_bin", nil, nil).
+						AddRow("table", "decimal", "decimal(6, 4)", "decimal comment", "NO", "YES", nil, "", "", "", "6", "4").
+						AddRow("table", "datetime", "datetime(5)", "datetime comment", "NO", "YES", nil, "", "", "", nil, nil).
+						AddRow("table", "point", "point", "point comment", "NO", "YES", nil, "", "", "", nil, nil).
+						AddRow("table", "json", "json", "json comment", "NO", "YES", nil, "", "", "", nil, nil).
+						AddRow("table", "enum", "enum('a','b','c')", "enum comment", "NO", "YES", nil, "", "", "", nil, nil))
+
+				//TABLE_NAME, INDEX_NAME, COLUMN_NAME, NON_UNIQUE, SEQ_IN_INDEX, INDEX_TYPE, COLLATION, INDEX_COMMENT, SUB_PART, EXPRESSION
+				f := IndexesQueryFields
 				mock.ExpectQuery(Escape(IndexesExprQuery)).
-					WithArgs("test", "table").
+					WithArgs("test").
 					WillReturnRows(sqlmock.NewRows(f).
-						AddRow("PRIMARY", "bigint", "0", "1", "BTREE", "", "", nil, nil).
-						AddRow("varchar_idx", "varchar", "0", "1", "BTREE", "", "", nil, nil).
-						AddRow("subpart", "varchar1", "0", "1", "BTREE", "", "", 64, nil).
-						AddRow("non_unique", "char", "1", "1", "BTREE", "", "", nil, nil).
-						AddRow("unique", "char", "0", "1", "BTREE", "", "", nil, nil).
-						AddRow("unique_union", "char", "0", "1", "BTREE", "", "", nil, nil).
-						AddRow("unique_union", "varchar1", "0", "2", "BTREE", "", "", nil, nil))
+						AddRow("table", "PRIMARY", "bigint", "0", "1", "BTREE", "", "", nil, nil).
+						AddRow("table", "varchar_idx", "varchar", "0", "1", "BTREE", "", "", nil, nil).
+						AddRow("table", "subpart", "varchar1", "0", "1", "BTREE", "", "", 64, nil).
+						AddRow("table", "non_unique", "char", "1", "1", "BTREE", "", "", nil, nil).
+						AddRow("table", "unique", "char", "0", "1", "BTREE", "", "", nil, nil).
+						AddRow("table", "unique_union", "char", "0", "1", "BTREE", "", "", nil, nil).
+						AddRow("table", "unique_union", "varchar1", "0", "2", "BTREE", "", "", nil, nil).
+						AddRow("table", "functional", nil, "1", "1", "BTREE", "", "", nil, "lower(`varchar`)"))
 
 				//"CONSTRAINT_NAME", "TABLE_NAME", "COLUMN_NAME", "TABLE_SCHEMA", "REFERENCED_TABLE_NAME", "REFERENCED_COLUMN_NAME", "REFERENCED_TABLE_SCHEMA", "UPDATE_RULE", "DELETE_RULE"
 				mock.ExpectQuery(Escape(ForeignKeysQuery)).
-					WithArgs("test", "table").
+					WithArgs("test").
 					WillReturnRows(sqlmock.NewRows(ForeignKeysQueryFields))
 
 			},
@@ -190,6 +262,16 @@ func TestTables(t *testing.T) {
 							Type:   "BTREE",
 							Unique: true,
 						},
+						{
+							Name: "functional",
+							IndexColumns: []*schema.IndexColumn{
+								{
+									SeqNo: 1,
+									Expr:  "lower(`varchar`)",
+								},
+							},
+							Type: "BTREE",
+						},
 					},
 				}
 
@@ -321,39 +403,28 @@ func TestTables(t *testing.T) {
 
 				//COLUMN_NAME, COLUMN_TYPE, COLUMN_COMMENT, IS_NULLABLE, COLUMN_KEY, COLUMN_DEFAULT, EXTRA, CHARACTER_SET_NAME, COLLATION_NAME, NUMERIC_PRECISION, NUMERIC_SCALE
 				mock.ExpectQuery(Escape(ColumnsQuery)).
-					WithArgs("test", "table").
-					WillReturnRows(sqlmock.NewRows(ColumnsQueryFields).
-						AddRow("id", "bigint(20)", "id comment", "NO", "PRI", nil, "auto_increment", "", "", nil, nil).
-						AddRow("gid", "bigint(20)", "gid comment", "NO", "MUL", nil, "", "", "", nil, nil).
-						AddRow("cid", "bigint(20)", "cid comment", "NO", "MUL", nil, "", "", "", nil, nil).
-						AddRow("uid", "bigint(20)", "uid comment", "NO", "MUL", nil, "", "", "", nil, nil))
-				mock.ExpectQuery(Escape(ColumnsQuery)).
-					WithArgs("test", "fk").
+					WithArgs("test").
 					WillReturnRows(sqlmock.NewRows(ColumnsQueryFields).
-						AddRow("id", "bigint(20)", "id comment", "NO", "PRI", nil, "auto_increment", "", "", nil, nil).
-						AddRow("cid", "bigint(20)", "cid comment", "NO", "MUL", nil, "", "", "", nil, nil))
-
-				//INDEX_NAME, COLUMN_NAME, NON_UNIQUE, SEQ_IN_INDEX, INDEX_TYPE, COLLATION, INDEX_COMMENT, SUB_PART, EXPRESSION
-				f := append(IndexesQueryFields, "`EXPRESSION`")
+						AddRow("fk", "id", "bigint(20)", "id comment", "NO", "PRI", nil, "auto_increment", "", "", nil, nil).
+						AddRow("fk", "cid", "bigint(20)", "cid comment", "NO", "MUL", nil, "", "", "", nil, nil).
+						AddRow("table", "id", "bigint(20)", "id comment", "NO", "PRI", nil, "auto_increment", "", "", nil, nil).
+						AddRow("table", "gid", "bigint(20)", "gid comment", "NO", "MUL", nil, "", "", "", nil, nil).
+						AddRow("table", "cid", "bigint(20)", "cid comment", "NO", "MUL", nil, "", "", "", nil, nil).
+						AddRow("table", "uid", "bigint(20)", "uid comment", "NO", "MUL", nil, "", "", "", nil, nil))
+
+				//TABLE_NAME, INDEX_NAME, COLUMN_NAME, NON_UNIQUE, SEQ_IN_INDEX, INDEX_TYPE, COLLATION, INDEX_COMMENT, SUB_PART, EXPRESSION
 				mock.ExpectQuery(Escape(IndexesExprQuery)).
-					WithArgs("test", "table").
-					WillReturnRows(sqlmock.NewRows(f))
-				mock.ExpectQuery(Escape(IndexesExprQuery)).
-					WithArgs("test", "fk").
-					WillReturnRows(sqlmock.NewRows(f))
+					WithArgs("test").
+					WillReturnRows(sqlmock.NewRows(IndexesQueryFields))
 
 				//"CONSTRAINT_NAME", "TABLE_NAME", "COLUMN_NAME", "TABLE_SCHEMA", "REFERENCED_TABLE_NAME", "REFERENCED_COLUMN_NAME", "REFERENCED_TABLE_SCHEMA", "UPDATE_RULE", "DELETE_RULE"
 				mock.ExpectQuery(Escape(ForeignKeysQuery)).
-					WithArgs("test", "table").
+					WithArgs("test").
 					WillReturnRows(sqlmock.NewRows(ForeignKeysQueryFields).
 						AddRow("multi_column", "table", "gid", "test", "fk", "id", "test", "NO ACTION", "CASCADE").
 						AddRow("multi_column", "table", "cid", "test", "fk", "cid", "test", "NO ACTION", "CASCADE").
 						AddRow("self_reference", "table", "uid", "test", "table", "id", "test", "NO ACTION", "CASCADE"))
 
-				mock.ExpectQuery(Escape(ForeignKeysQuery)).
-					WithArgs("test", "fk").
-					WillReturnRows(sqlmock.NewRows(ForeignKeysQueryFields))
-
 			},
 			expected: func() *schema.Schema {
 				s := &schema.Schema{
@@ -71,14 +71,15 @@ var (
 	TablesQueryFields = []string{"t1.table_name", "t2.character_set_name", "t1.table_collation", "t1.auto_increment", "t1.table_comment", "t1.create_options"}
 	TablesQuery       = "SELECT " + strings.Join(TablesQueryFields, ",") + " FROM information_schema.tables AS t1 JOIN information_schema.collations AS t2 ON t1.table_collation = t2.collation_name WHERE table_schema = ?"
 
-	// ColumnsQuery 列查询语句
-	ColumnsQueryFields = []string{"column_name", "column_type", "column_comment", "is_nullable", "column_key", "column_default", "extra", "character_set_name", "collation_name", "numeric_precision", "numeric_scale"}
-	ColumnsQuery       = "SELECT " + strings.Join(ColumnsQueryFields, ",") + " FROM information_schema.columns WHERE table_schema = ? AND table_name = ? ORDER BY ordinal_position"
+	// ColumnsQuery 列查询语句，一次查询整个 schema 的所有列，按表分组
+	ColumnsQueryFields = []string{"table_name", "column_name", "column_type", "column_comment", "is_nullable", "column_key", "column_default", "extra", "character_set_name", "collation_name", "numeric_precision", "numeric_scale"}
+	ColumnsQuery       = "SELECT " + strings.Join(ColumnsQueryFields, ",") + " FROM information_schema.columns WHERE table_schema = ? ORDER BY table_name, ordinal_position"
 
-	// IndexesQuery 索引查询语句
-	IndexesQueryFields = []string{"index_name", "column_name", "non_unique", "seq_in_index", "index_type", "collation", "index_comment", "sub_part", "expression"}
+	// IndexesQuery 索引查询语句，一次查询整个 schema 的所有索引
+	IndexesQueryFields = []string{"table_name", "index_name", "column_name", "non_unique", "seq_in_index", "index_type", "collation", "index_comment", "sub_part", "expression"}
 	IndexesQuery       = `
 SELECT 
+	table_name,
 	index_name,
 	column_name,
 	non_unique,
@@ -92,12 +93,12 @@ FROM
 	information_schema.statistics 
 WHERE 
 	table_schema = ? 
-	AND table_name = ? 
 ORDER BY 
-	index_name, seq_in_index
+	table_name, index_name, seq_in_index
 `
 	IndexesExprQuery = `
 SELECT 
+	table_name,
 	index_name,
 	column_name,
 	non_unique,
@@ -111,12 +112,11 @@ FROM
 	information_schema.statistics 
 WHERE 
 	table_schema = ? 
-	AND table_name = ? 
 ORDER BY 
-	index_name, seq_in_index
+	table_name, index_name, seq_in_index
 `
 
-	// ForeignKeysQuery 外键查询语句
+	// ForeignKeysQuery 外键查询语句，一次查询整个 schema 的所有外键
 	ForeignKeysQueryFields = []string{"t1.constraint_name", "t1.table_name", "t1.column_name", "t1.table_schema", "t1.referenced_table_name", "t1.referenced_column_name", "t1.referenced_table_schema", "t3.update_rule", "t3.delete_rule"}
 	ForeignKeysQuery       = `
 SELECT ` + strings.Join(ForeignKeysQueryFields, ",") +
@@ -131,8 +131,8 @@ SELECT ` + strings.Join(ForeignKeysQueryFields, ",") +
 WHERE
 	t2.constraint_type = 'FOREIGN KEY'
 	AND t1.table_schema = ?
-	AND t1.table_name = ?
 ORDER BY
+	t1.table_name,
 	t1.constraint_name,
 	t1.ordinal_position`
 )
@@ -262,5 +262,7 @@ func parseExtra(c *schema.Column, extra string) {
 		c.AutoIncrement = true
 	case "default_generated on update current_timestamp", "on update current_timestamp", "on update current_timestamp()":
 		c.OnUpdate = true
+	case "virtual generated", "stored generated":
+		c.Generated = true
 	}
 }
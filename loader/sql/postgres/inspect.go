@@ -4,6 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
 	"golang.org/x/mod/semver"
 
@@ -16,6 +19,10 @@ type inspect struct {
 	Driver cre.Driver
 
 	schema *schema.Schema
+	// schemas 需要加载的 schema 列表，为空时仅加载 CURRENT_SCHEMA()
+	schemas []string
+	// tableMap 按 schema 限定的表名索引，批量查询的结果按表分组
+	tableMap map[string]*schema.Table
 
 	// Database information
 	version string
@@ -25,8 +32,9 @@ type inspect struct {
 
 var _ schema.Inspector = (*inspect)(nil)
 
-func NewInspector(drv cre.Driver) schema.Inspector {
-	return &inspect{Driver: drv}
+// NewInspector returns the postgres inspector, 指定多个 schema 时一并加载，外键可跨 schema 引用
+func NewInspector(drv cre.Driver, schemas ...string) schema.Inspector {
+	return &inspect{Driver: drv, schemas: schemas}
 }
 func (i *inspect) Inspect(ctx context.Context, name string) (*schema.Schema, error) {
 	i.schema = &schema.Schema{Name: name}
@@ -41,7 +49,11 @@ func (i *inspect) Inspect(ctx context.Context, name string) (*schema.Schema, err
 		return nil, err
 	}
 	i.schema.Tables = tables
+	if len(tables) == 0 {
+		return i.schema, nil
+	}
 
+	// 列、索引和外键均为批量查询，查询次数与表的数量无关
 	err = i.inspectColumns(ctx)
 	if err != nil {
 		return nil, err
@@ -91,19 +103,20 @@ func (i *inspect) dbInfo(ctx context.Context) error {
 
 func (i *inspect) tables(ctx context.Context) ([]*schema.Table, error) {
 	var (
-		name    string
-		comment sql.NullString
+		namespace, name string
+		comment         sql.NullString
 	)
 
-	rows, err := i.querySqlRows(ctx, TablesQuery)
+	rows, err := i.querySqlRows(ctx, TablesQuery, strings.Join(i.schemas, ","))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	var tables []*schema.Table
+	i.tableMap = make(map[string]*schema.Table)
 	for rows.Next() {
-		if err := rows.Scan(&name, &comment); err != nil {
+		if err := rows.Scan(&namespace, &name, &comment); err != nil {
 			if err == sql.ErrNoRows {
 				return nil, fmt.Errorf("postgres/tables: no tables found for schema [%q]", i.schema.Name)
 			}
@@ -111,14 +124,16 @@ func (i *inspect) tables(ctx context.Context) ([]*schema.Table, error) {
 		}
 
 		table := &schema.Table{
-			Name:   name,
-			Schema: i.schema,
+			Name:      name,
+			Namespace: namespace,
+			Schema:    i.schema,
 		}
 
 		if comment.Valid {
 			table.Comment = comment.String
 		}
 		tables = append(tables, table)
+		i.tableMap[table.QualifiedName()] = table
 
 	}
 
@@ -129,57 +144,45 @@ func (i *inspect) tables(ctx context.Context) ([]*schema.Table, error) {
 	return tables, nil
 }
 
-func (i *inspect) inspectColumns(ctx context.Context) error {
-	for _, table := range i.schema.Tables {
-		columns, err := i.columns(ctx, table)
-		if err != nil {
-			return fmt.Errorf("postgres/columns: columns [%w]", err)
-		}
-		table.Columns = columns
-	}
-	return nil
-}
-
-func (i *inspect) inspectIndexes(ctx context.Context) error {
-	for _, table := range i.schema.Tables {
-		indexes, err := i.indexes(ctx, table.Name)
-		if err != nil {
-			return fmt.Errorf("postgres/indexes: indexes [%w]", err)
+// namespaces returns the comma separated namespaces of the loaded tables, 作为批量查询的参数
+func (i *inspect) namespaces() string {
+	var namespaces []string
+	seen := make(map[string]bool)
+	for _, t := range i.schema.Tables {
+		if !seen[t.Namespace] {
+			seen[t.Namespace] = true
+			namespaces = append(namespaces, t.Namespace)
 		}
-		table.Indexes = indexes
 	}
-	return nil
+	return strings.Join(namespaces, ",")
 }
 
-func (i *inspect) inspectForeignKeys(ctx context.Context) error {
-	for _, table := range i.schema.Tables {
-		foreignKeys, err := i.foreignKeys(ctx, table)
-		if err != nil {
-			return fmt.Errorf("postgres/fks: fks [%w]", err)
-		}
-		table.ForeignKeys = foreignKeys
-	}
-	return nil
-}
-
-func (i *inspect) columns(ctx context.Context, t *schema.Table) ([]*schema.Column, error) {
-	args := []any{t.Name}
-
-	rows, err := i.querySqlRows(ctx, ColumnsQuery, args...)
+// inspectColumns 一次查询所有表的列，按 schema 限定的表名分组
+func (i *inspect) inspectColumns(ctx context.Context) error {
+	rows, err := i.querySqlRows(ctx, ColumnsQuery, i.namespaces())
 	if err != nil {
-		return nil, fmt.Errorf("postgres/columns: query rows [%w]", err)
+		return fmt.Errorf("postgres/columns: query rows [%w]", err)
 	}
 	defer rows.Close()
 
-	var columns []*schema.Column
+	// 枚举类型 oid => 列，列加载完成后一次查询所有枚举值
+	enums := make(map[int64][]*schema.Column)
 	for rows.Next() {
 		var (
 			typid, maxlen, precision, scale                                               sql.NullInt64
+			tableSchema, tableName                                                        string
 			name, dataType, nullable, defaults, udt, charset, collation, comment, typtype sql.NullString
+			identity, generated                                                           sql.NullString
+			onUpdate                                                                      sql.NullBool
 		)
 
-		if err := rows.Scan(&name, &dataType, &comment, &nullable, &defaults, &charset, &collation, &precision, &scale, &maxlen, &udt, &typtype, &typid); err != nil {
-			return nil, fmt.Errorf("postgres/columns: scan rows [%w]", err)
+		if err := rows.Scan(&tableSchema, &tableName, &name, &dataType, &comment, &nullable, &defaults, &charset, &collation, &precision, &scale, &maxlen, &udt, &typtype, &typid, &identity, &generated, &onUpdate); err != nil {
+			return fmt.Errorf("postgres/columns: scan rows [%w]", err)
+		}
+
+		t, ok := i.tableMap[spec.QualifiedName(tableSchema, tableName)]
+		if !ok {
+			continue
 		}
 
 		ci := &columnInfo{}
@@ -214,7 +217,7 @@ func (i *inspect) columns(ctx context.Context, t *schema.Table) ([]*schema.Colum
 
 		ct, err := parseType(ci)
 		if err != nil {
-			return nil, fmt.Errorf("postgres/columns: parse type [%s, %w]", t.Name, err)
+			return fmt.Errorf("postgres/columns: parse type [%s, %w]", t.Name, err)
 		}
 		column := &schema.Column{
 			Type:    ct,
@@ -247,67 +250,91 @@ func (i *inspect) columns(ctx context.Context, t *schema.Table) ([]*schema.Colum
 		case *spec.FloatType:
 			column.Precision = int(precision.Int64)
 			column.Scale = int(scale.Int64)
+		case *spec.EnumType:
+			enums[typid.Int64] = append(enums[typid.Int64], column)
+		}
 
+		// serial 类型的默认值为 nextval('seq'::regclass)，与 MySQL auto_increment 一致不保留默认值
+		if identity.String == "YES" || isSerial(defaults) {
+			column.AutoIncrement = true
+			column.Default = sql.NullString{}
 		}
-		i.enumValues(ctx, typid.Int64, column)
-		columns = append(columns, column)
+		column.Generated = generated.String == "ALWAYS"
+		column.OnUpdate = onUpdate.Bool
+
+		t.Columns = append(t.Columns, column)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("postgres/columns: rows [%w]", err)
+		return fmt.Errorf("postgres/columns: rows [%w]", err)
 	}
 
-	return columns, nil
+	return i.enumValues(ctx, enums)
 }
 
-func (i *inspect) enumValues(ctx context.Context, id int64, column *schema.Column) error {
-	if _, ok := column.Type.(*spec.EnumType); !ok {
-		return fmt.Errorf("postgres/enumValues: column [%s] is not an enum", column.Name)
+// enumValues 一次查询所有枚举类型的值
+func (i *inspect) enumValues(ctx context.Context, enums map[int64][]*schema.Column) error {
+	if len(enums) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(enums))
+	for id := range enums {
+		ids = append(ids, strconv.FormatInt(id, 10))
 	}
+	sort.Strings(ids)
 
-	rows, err := i.querySqlRows(ctx, EnumQuery, id)
+	rows, err := i.querySqlRows(ctx, EnumQuery, strings.Join(ids, ","))
 	if err != nil {
 		return fmt.Errorf("postgres/enumValues: query rows [%w]", err)
 	}
 	defer rows.Close()
 
-	var values []string
 	for rows.Next() {
-		var v string
-		if err := rows.Scan(&v); err != nil {
+		var (
+			id int64
+			v  string
+		)
+		if err := rows.Scan(&id, &v); err != nil {
 			return fmt.Errorf("postgres/enumValues: scan rows [%w]", err)
 		}
-		values = append(values, v)
+		for _, column := range enums[id] {
+			enum := column.Type.(*spec.EnumType)
+			enum.Values = append(enum.Values, v)
+		}
 	}
-	column.Type.(*spec.EnumType).Values = values
 
-	return nil
+	return rows.Err()
 }
 
-func (i *inspect) indexes(ctx context.Context, name string) ([]*schema.Index, error) {
-	args := []any{name}
-
-	rows, err := i.querySqlRows(ctx, IndexesQuery, args...)
+// inspectIndexes 一次查询所有表的索引
+func (i *inspect) inspectIndexes(ctx context.Context) error {
+	rows, err := i.querySqlRows(ctx, IndexesQuery, i.namespaces())
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("postgres/indexes: indexes [%w]", err)
 	}
 	defer rows.Close()
 
-	var indexs []*schema.Index
-	indexMap := make(map[string]*schema.Index)
+	indexMap := make(map[[2]string]*schema.Index)
 	for rows.Next() {
 		var (
-			name, idxType                                          string
+			tableSchema, tableName, name, idxType                  string
 			column, constraintType, predicate, expression, comment sql.NullString
 			primary, unique                                        bool
 			asc, desc, nullsFirst, nullsLast                       sql.NullBool
 		)
 
-		if err := rows.Scan(&name, &idxType, &column, &primary, &unique, &constraintType, &predicate, &expression, &asc, &desc, &nullsFirst, &nullsLast, &comment); err != nil {
-			return nil, fmt.Errorf("postgres/indexes: scanning index: %w", err)
+		if err := rows.Scan(&tableSchema, &tableName, &name, &idxType, &column, &primary, &unique, &constraintType, &predicate, &expression, &asc, &desc, &nullsFirst, &nullsLast, &comment); err != nil {
+			return fmt.Errorf("postgres/indexes: scanning index: %w", err)
 		}
 
-		index, ok := indexMap[name]
+		table := spec.QualifiedName(tableSchema, tableName)
+		t, ok := i.tableMap[table]
+		if !ok {
+			continue
+		}
+
+		index, ok := indexMap[[2]string{table, name}]
 		if !ok {
 			index = &schema.Index{
 				Name:    name,
@@ -319,88 +346,85 @@ func (i *inspect) indexes(ctx context.Context, name string) ([]*schema.Index, er
 				index.Primary = true
 			}
 
-			indexMap[name] = index
-			indexs = append(indexs, index)
+			indexMap[[2]string{table, name}] = index
+			t.Indexes = append(t.Indexes, index)
 		}
 
 		idxCol := &schema.IndexColumn{
 			SeqNo:  len(index.IndexColumns) + 1,
 			Column: column.String,
 		}
+		// 表达式索引，如 lower(email)
+		if expression.Valid {
+			idxCol.Column, idxCol.Expr = "", expression.String
+		}
 
 		index.IndexColumns = append(index.IndexColumns, idxCol)
 
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return indexs, nil
+	return rows.Err()
 }
 
-func (i *inspect) foreignKeys(ctx context.Context, t *schema.Table) ([]*schema.ForeignKey, error) {
-	args := []any{t.Name}
-
-	query := ForeignKeysQuery
-
-	rows, err := i.querySqlRows(ctx, query, args...)
+// inspectForeignKeys 一次查询所有表的外键，被引用表可以位于其他已加载的 schema
+func (i *inspect) inspectForeignKeys(ctx context.Context) error {
+	rows, err := i.querySqlRows(ctx, ForeignKeysQuery, i.namespaces())
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("postgres/fks: fks [%w]", err)
 	}
 	defer rows.Close()
 
-	var foreignKeys []*schema.ForeignKey
-	foreignKeysMap := make(map[string]*schema.ForeignKey)
+	foreignKeysMap := make(map[[2]string]*schema.ForeignKey)
 	for rows.Next() {
 		var (
 			name, table, column, tableSchema, refTable, refColumn, refSchema, updateRule, deleteRule string
 		)
 		if err := rows.Scan(&name, &table, &column, &tableSchema, &refTable, &refColumn, &refSchema, &updateRule, &deleteRule); err != nil {
-			return nil, fmt.Errorf("postgres/fks: scanning fk: %w", err)
+			return fmt.Errorf("postgres/fks: scanning fk: %w", err)
 		}
 
-		foreignKey, ok := foreignKeysMap[name]
-		rt := t.Schema.Table(refTable)
-		if rt == nil {
+		t, ok := i.tableMap[spec.QualifiedName(tableSchema, table)]
+		if !ok {
+			continue
+		}
 
-			return nil, fmt.Errorf("postgres/fks: ref table %q not found for fk %q", refTable, name)
+		key := [2]string{t.QualifiedName(), name}
+		foreignKey, ok := foreignKeysMap[key]
+		// 被引用表所在的 schema 未加载时无法解析
+		rt := i.tableMap[spec.QualifiedName(refSchema, refTable)]
+		if rt == nil {
+			return fmt.Errorf("postgres/fks: ref table %q not found for fk %q", spec.QualifiedName(refSchema, refTable), name)
 		}
 
 		if !ok {
 			foreignKey = &schema.ForeignKey{
-				Name:  name,
-				Table: t,
-				//目前只支持引用同一个数据库的外键
+				Name:     name,
+				Table:    t,
 				RefTable: rt,
 				OnUpdate: schema.ReferenceOption(updateRule),
 				OnDelete: schema.ReferenceOption(deleteRule),
 			}
 
-			foreignKeysMap[name] = foreignKey
-			foreignKeys = append(foreignKeys, foreignKey)
+			foreignKeysMap[key] = foreignKey
+			t.ForeignKeys = append(t.ForeignKeys, foreignKey)
 		}
 
 		c := t.Column(column)
 		if c == nil {
-			return nil, fmt.Errorf("postgres/fks: column %q not found for fk %q", column, foreignKey.Name)
+			return fmt.Errorf("postgres/fks: column %q not found for fk %q", column, foreignKey.Name)
 
 		}
 		foreignKey.Columns = append(foreignKey.Columns, c)
 
 		rc := foreignKey.RefTable.Column(refColumn)
 		if rc == nil {
-			return nil, fmt.Errorf("postgres/fks: ref column %q not found for fk %q", refColumn, foreignKey.Name)
+			return fmt.Errorf("postgres/fks: ref column %q not found for fk %q", refColumn, foreignKey.Name)
 		}
 		foreignKey.RefColumns = append(foreignKey.RefColumns, rc)
 
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return foreignKeys, nil
+	return rows.Err()
 }
 
 func (i *inspect) querySqlRows(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
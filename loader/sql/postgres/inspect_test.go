@@ -2,6 +2,8 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"regexp"
 	"strings"
 	"testing"
@@ -42,10 +44,85 @@ func (m postgresMock) noForeignKeys() {
 	m.ExpectQuery(Escape(ForeignKeysQuery)).
 		WillReturnRows(sqlmock.NewRows(ForeignKeysQueryFields))
 }
+
+// tables mocks n tables, 每个表的 parent_id 引用前一个表，status 为枚举类型
+func (m postgresMock) tables(n int) {
+	m.info()
+
+	tables := sqlmock.NewRows(TablesQueryFields)
+	columns := sqlmock.NewRows(ColumnsQueryFields)
+	indexes := sqlmock.NewRows(IndexesQueryFields)
+	fks := sqlmock.NewRows(ForeignKeysQueryFields)
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("t%d", i)
+		tables.AddRow("public", name, nil)
+		columns.AddRow("public", name, "id", "bigint", nil, "NO", nil, nil, nil, 64, 0, nil, "int8", "b", 20, "YES", "NEVER", false).
+			AddRow("public", name, "status", "USER-DEFINED", nil, "NO", nil, nil, nil, nil, nil, nil, "status", "e", 16384, "NO", "NEVER", false).
+			AddRow("public", name, "parent_id", "bigint", nil, "YES", nil, nil, nil, 64, 0, nil, "int8", "b", 20, "NO", "NEVER", false)
+		indexes.AddRow("public", name, name+"_pkey", "btree", "id", true, true, "p", nil, nil, true, false, false, true, nil)
+		if i > 0 {
+			fks.AddRow(name+"_parent_id_fkey", name, "parent_id", "public", fmt.Sprintf("t%d", i-1), "id", "public", "NO ACTION", "CASCADE")
+		}
+	}
+
+	m.ExpectQuery(Escape(TablesQuery)).WillReturnRows(tables)
+	m.ExpectQuery(Escape(ColumnsQuery)).WithArgs("public").WillReturnRows(columns)
+	m.ExpectQuery(Escape(EnumQuery)).WithArgs("16384").
+		WillReturnRows(sqlmock.NewRows(EnumQueryFields).AddRow(16384, "active").AddRow(16384, "disabled"))
+	m.ExpectQuery(Escape(IndexesQuery)).WithArgs("public").WillReturnRows(indexes)
+	m.ExpectQuery(Escape(ForeignKeysQuery)).WithArgs("public").WillReturnRows(fks)
+}
+
+// countDriver counts the queries sent to the database.
+type countDriver struct {
+	cre.Driver
+	queries int
+}
+
+func (d *countDriver) Query(ctx context.Context, query string, args ...any) (any, error) {
+	d.queries++
+	return d.Driver.Query(ctx, query, args...)
+}
+
+func (d *countDriver) QueryRow(ctx context.Context, query string, args ...any) (any, error) {
+	d.queries++
+	return d.Driver.QueryRow(ctx, query, args...)
+}
+
+// BenchmarkInspect 查询次数与表和枚举列的数量无关，各规模下 queries/op 保持不变
+func BenchmarkInspect(b *testing.B) {
+	for _, n := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("tables=%d", n), func(b *testing.B) {
+			var queries int
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				db, mock, err := sqlmock.New()
+				require.NoError(b, err)
+				postgresMock{mock}.tables(n)
+				drv := &countDriver{Driver: schema.OpenDB(cre.Postgres, db)}
+				b.StartTimer()
+
+				s, err := (&inspect{Driver: drv}).Inspect(context.Background(), "public")
+
+				b.StopTimer()
+				require.NoError(b, err)
+				require.Len(b, s.Tables, n)
+				require.Equal(b, []string{"active", "disabled"}, s.Tables[n-1].Column("status").Type.(*spec.EnumType).Values)
+				require.NoError(b, mock.ExpectationsWereMet())
+				queries = drv.queries
+				db.Close()
+				b.StartTimer()
+			}
+			b.ReportMetric(float64(queries), "queries/op")
+		})
+	}
+}
+
 func TestInspectTable(t *testing.T) {
 	schemaName, tableName, fkTableName := "test", "table", "fk"
 	tests := []struct {
 		name     string
+		schemas  []string
 		before   func(postgresMock)
 		expected func() *schema.Schema
 		wantErr  bool
@@ -55,7 +132,7 @@ func TestInspectTable(t *testing.T) {
 			before: func(mock postgresMock) {
 				mock.info()
 				mock.ExpectQuery(Escape(TablesQuery)).
-					WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "COMMENT"}))
+					WillReturnRows(sqlmock.NewRows(TablesQueryFields))
 			},
 			expected: func() *schema.Schema {
 				return &schema.Schema{
@@ -69,64 +146,65 @@ func TestInspectTable(t *testing.T) {
 				mock.info()
 
 				mock.ExpectQuery(Escape(TablesQuery)).
-					WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "COMMENT"}).
-						AddRow(tableName, "Comment"))
+					WillReturnRows(sqlmock.NewRows(TablesQueryFields).
+						AddRow(schemaName, tableName, "Comment"))
 
 					// http://www.postgres.cn/docs/14/datatype.html
-					// column_name, data_type, comment, is_nullable, column_default, character_set_name, collation_name, numeric_precision, numeric_scale, character_maximum_length, udt_name, typtype, oid
+					// table_schema, table_name, column_name, data_type, comment, is_nullable, column_default, character_set_name, collation_name, numeric_precision, numeric_scale, character_maximum_length, udt_name, typtype, oid, is_identity, is_generated, on_update
 				mock.ExpectQuery(Escape(ColumnsQuery)).
-					WithArgs(tableName).
+					WithArgs(schemaName).
 					WillReturnRows(sqlmock.NewRows(ColumnsQueryFields).
-						AddRow("bigint", "bigint", "bigint comment", "NO", nil, nil, nil, 64, 0, nil, "int8", "b", 1).
-						AddRow("bigserial", "bigserial", "bigserial comment", "NO", nil, nil, nil, 64, 0, nil, "serial8", "b", 2).
-						AddRow("bit", "bit", "bit comment", "YES", nil, nil, nil, nil, nil, 1, "bit", "b", 3).
-						AddRow("bit varying", "bit varying", "bit varying comment", "YES", nil, nil, nil, nil, nil, 255, "varbit", "b", 4).
-						AddRow("boolean", "boolean", "boolean comment", "YES", nil, nil, nil, nil, nil, nil, "bool", "b", 5).
-						AddRow("box", "box", "box comment", "YES", nil, nil, nil, nil, nil, nil, nil, "b", 6).
-						AddRow("bytea", "bytea", "bytea comment", "YES", nil, nil, nil, nil, nil, 1, nil, "b", 7).
-						AddRow("character", "character", "character comment", "YES", nil, nil, nil, nil, nil, 1, "bpchar", "b", 8).
-						AddRow("character varying", "character varying", "character varying comment", "YES", nil, nil, nil, nil, nil, 255, "varchar", "b", 9).
-						AddRow("cidr", "cidr", "cidr comment", "YES", nil, nil, nil, nil, nil, nil, "cidr", "b", 10).
-						AddRow("circle", "circle", "circle comment", "YES", nil, nil, nil, nil, nil, nil, "circle", "b", 11).
-						AddRow("date", "date", "date comment", "YES", nil, nil, nil, nil, nil, nil, "date", "b", 12).
-						AddRow("double precision", "double precision", "double precision comment", "YES", nil, nil, nil, nil, nil, nil, "float8", "b", 13).
-						AddRow("inet", "inet", "inet comment", "YES", nil, nil, nil, nil, nil, nil, "inet", "b", 14).
-						AddRow("integer", "integer", "integer comment", "YES", nil, nil, nil, 16, 0, nil, "int4", "b", 15).
-						AddRow("interval", "interval", "interval comment", "YES", nil, nil, nil, nil, nil, nil, "interval", "b", 16).
-						AddRow("json", "json", "json comment", "YES", nil, nil, nil, nil, nil, nil, "json", "b", 17).
-						AddRow("jsonb", "jsonb", "jsonb comment", "YES", nil, nil, nil, nil, nil, nil, "jsonb", "b", 18).
-						AddRow("line", "line", "line comment", "YES", nil, nil, nil, nil, nil, nil, "line", "b", 19).
-						AddRow("lseg", "lseg", "lseg comment", "YES", nil, nil, nil, nil, nil, nil, "lseg", "b", 20).
-						AddRow("macaddr", "macaddr", "macaddr comment", "YES", nil, nil, nil, nil, nil, nil, "macaddr", "b", 21).
-						AddRow("macaddr8", "macaddr8", "macaddr8 comment", "YES", nil, nil, nil, nil, nil, nil, "macaddr8", "b", 22).
-						AddRow("money", "money", "money comment", "YES", nil, nil, nil, nil, nil, nil, "money", "b", 23).
-						AddRow("numeric", "numeric", "numeric comment", "YES", nil, nil, nil, nil, nil, nil, "numeric", "b", 24).
-						AddRow("path", "path", "path comment", "YES", nil, nil, nil, nil, nil, nil, "path", "b", 25).
-						AddRow("pg_lsn", "pg_lsn", "pg_lsn comment", "YES", nil, nil, nil, nil, nil, nil, "pg_lsn", "b", 26).
-						AddRow("pg_snapshot", "pg_snapshot", "pg_snapshot comment", "YES", nil, nil, nil, nil, nil, nil, "pg_snapshot", "b", 27).
-						AddRow("point", "point", "point comment", "YES", nil, nil, nil, nil, nil, nil, "point", "b", 28).
-						AddRow("polygon", "polygon", "polygon comment", "YES", nil, nil, nil, nil, nil, nil, "polygon", "b", 29).
-						AddRow("real", "real", "real comment", "YES", nil, nil, nil, nil, nil, nil, "float4", "b", 30).
-						AddRow("smallint", "smallint", "smallint comment", "YES", nil, nil, nil, 16, 0, nil, "int2", "b", 31).
-						AddRow("smallserial", "smallserial", "smallserial comment", "YES", nil, nil, nil, 16, 0, nil, "serial2", "b", 32).
-						AddRow("serial", "serial", "serial comment", "YES", nil, nil, nil, 32, 0, nil, "serial4", "b", 33).
-						AddRow("text", "text", "text comment", "YES", nil, nil, nil, nil, nil, nil, "text", "b", 34).
-						AddRow("time", "time without time zone", "time comment", "YES", nil, nil, nil, nil, nil, nil, "time", "b", 35).
-						AddRow("time without time zone", "time without time zone", "time without time zone comment", "YES", nil, nil, nil, nil, nil, nil, "time", "b", 36).
-						AddRow("time with time zone", "time with time zone", "time with time zone comment", "YES", nil, nil, nil, nil, nil, nil, "time", "b", 37).
-						AddRow("timestamp", "timestamp without time zone", "timestamp comment", "YES", nil, nil, nil, nil, nil, nil, "timestamp", "b", 38).
-						AddRow("timestamp without time zone", "timestamp without time zone", "timestamp without time zone comment", "YES", nil, nil, nil, nil, nil, nil, "timestamp", "b", 39).
-						AddRow("timestamp with time zone", "timestamp with time zone", "timestamp with time zone comment", "YES", nil, nil, nil, nil, nil, nil, "timestamptz", "b", 40).
-						AddRow("tsquery", "tsquery", "tsquery comment", "YES", nil, nil, nil, nil, nil, nil, "tsquery", "b", 41).
-						AddRow("tsvector", "tsvector", "tsvector comment", "YES", nil, nil, nil, nil, nil, nil, "tsvector", "b", 42).
-						AddRow("uuid", "uuid", "uuid comment", "YES", nil, nil, nil, nil, nil, nil, "uuid", "b", 43).
-						AddRow("xml", "xml", "xml comment", "YES", nil, nil, nil, nil, nil, nil, "xml", "b", 44).
-						AddRow("user-defined", "user-defined", "user-defined comment", "YES", nil, nil, nil, nil, nil, nil, "ltree", "b", 45).
-						AddRow("enum", "user-defined", "enum comment", "YES", nil, nil, nil, nil, nil, nil, "enum", "e", 46))
+						AddRow(schemaName, tableName, "bigint", "bigint", "bigint comment", "NO", nil, nil, nil, 64, 0, nil, "int8", "b", 1, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "bigserial", "bigserial", "bigserial comment", "NO", nil, nil, nil, 64, 0, nil, "serial8", "b", 2, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "bit", "bit", "bit comment", "YES", nil, nil, nil, nil, nil, 1, "bit", "b", 3, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "bit varying", "bit varying", "bit varying comment", "YES", nil, nil, nil, nil, nil, 255, "varbit", "b", 4, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "boolean", "boolean", "boolean comment", "YES", nil, nil, nil, nil, nil, nil, "bool", "b", 5, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "box", "box", "box comment", "YES", nil, nil, nil, nil, nil, nil, nil, "b", 6, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "bytea", "bytea", "bytea comment", "YES", nil, nil, nil, nil, nil, 1, nil, "b", 7, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "character", "character", "character comment", "YES", nil, nil, nil, nil, nil, 1, "bpchar", "b", 8, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "character varying", "character varying", "character varying comment", "YES", nil, nil, nil, nil, nil, 255, "varchar", "b", 9, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "cidr", "cidr", "cidr comment", "YES", nil, nil, nil, nil, nil, nil, "cidr", "b", 10, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "circle", "circle", "circle comment", "YES", nil, nil, nil, nil, nil, nil, "circle", "b", 11, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "date", "date", "date comment", "YES", nil, nil, nil, nil, nil, nil, "date", "b", 12, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "double precision", "double precision", "double precision comment", "YES", nil, nil, nil, nil, nil, nil, "float8", "b", 13, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "inet", "inet", "inet comment", "YES", nil, nil, nil, nil, nil, nil, "inet", "b", 14, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "integer", "integer", "integer comment", "YES", nil, nil, nil, 16, 0, nil, "int4", "b", 15, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "interval", "interval", "interval comment", "YES", nil, nil, nil, nil, nil, nil, "interval", "b", 16, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "json", "json", "json comment", "YES", nil, nil, nil, nil, nil, nil, "json", "b", 17, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "jsonb", "jsonb", "jsonb comment", "YES", nil, nil, nil, nil, nil, nil, "jsonb", "b", 18, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "line", "line", "line comment", "YES", nil, nil, nil, nil, nil, nil, "line", "b", 19, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "lseg", "lseg", "lseg comment", "YES", nil, nil, nil, nil, nil, nil, "lseg", "b", 20, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "macaddr", "macaddr", "macaddr comment", "YES", nil, nil, nil, nil, nil, nil, "macaddr", "b", 21, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "macaddr8", "macaddr8", "macaddr8 comment", "YES", nil, nil, nil, nil, nil, nil, "macaddr8", "b", 22, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "money", "money", "money comment", "YES", nil, nil, nil, nil, nil, nil, "money", "b", 23, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "numeric", "numeric", "numeric comment", "YES", nil, nil, nil, nil, nil, nil, "numeric", "b", 24, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "path", "path", "path comment", "YES", nil, nil, nil, nil, nil, nil, "path", "b", 25, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "pg_lsn", "pg_lsn", "pg_lsn comment", "YES", nil, nil, nil, nil, nil, nil, "pg_lsn", "b", 26, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "pg_snapshot", "pg_snapshot", "pg_snapshot comment", "YES", nil, nil, nil, nil, nil, nil, "pg_snapshot", "b", 27, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "point", "point", "point comment", "YES", nil, nil, nil, nil, nil, nil, "point", "b", 28, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "polygon", "polygon", "polygon comment", "YES", nil, nil, nil, nil, nil, nil, "polygon", "b", 29, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "real", "real", "real comment", "YES", nil, nil, nil, nil, nil, nil, "float4", "b", 30, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "smallint", "smallint", "smallint comment", "YES", nil, nil, nil, 16, 0, nil, "int2", "b", 31, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "smallserial", "smallserial", "smallserial comment", "YES", nil, nil, nil, 16, 0, nil, "serial2", "b", 32, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "serial", "serial", "serial comment", "YES", nil, nil, nil, 32, 0, nil, "serial4", "b", 33, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "text", "text", "text comment", "YES", nil, nil, nil, nil, nil, nil, "text", "b", 34, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "time", "time without time zone", "time comment", "YES", nil, nil, nil, nil, nil, nil, "time", "b", 35, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "time without time zone", "time without time zone", "time without time zone comment", "YES", nil, nil, nil, nil, nil, nil, "time", "b", 36, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "time with time zone", "time with time zone", "time with time zone comment", "YES", nil, nil, nil, nil, nil, nil, "time", "b", 37, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "timestamp", "timestamp without time zone", "timestamp comment", "YES", nil, nil, nil, nil, nil, nil, "timestamp", "b", 38, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "timestamp without time zone", "timestamp without time zone", "timestamp without time zone comment", "YES", nil, nil, nil, nil, nil, nil, "timestamp", "b", 39, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "timestamp with time zone", "timestamp with time zone", "timestamp with time zone comment", "YES", nil, nil, nil, nil, nil, nil, "timestamptz", "b", 40, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "tsquery", "tsquery", "tsquery comment", "YES", nil, nil, nil, nil, nil, nil, "tsquery", "b", 41, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "tsvector", "tsvector", "tsvector comment", "YES", nil, nil, nil, nil, nil, nil, "tsvector", "b", 42, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "uuid", "uuid", "uuid comment", "YES", nil, nil, nil, nil, nil, nil, "uuid", "b", 43, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "xml", "xml", "xml comment", "YES", nil, nil, nil, nil, nil, nil, "xml", "b", 44, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "user-defined", "user-defined", "user-defined comment", "YES", nil, nil, nil, nil, nil, nil, "ltree", "b", 45, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "enum", "user-defined", "enum comment", "YES", nil, nil, nil, nil, nil, nil, "enum", "e", 46, "NO", "NEVER", false))
 
 				mock.ExpectQuery(Escape(EnumQuery)).
-					WillReturnRows(sqlmock.NewRows([]string{"enumlabel"}).
-						AddRow("a").AddRow("b").AddRow("c"))
+					WithArgs("46").
+					WillReturnRows(sqlmock.NewRows(EnumQueryFields).
+						AddRow(46, "a").AddRow(46, "b").AddRow(46, "c"))
 
 				mock.noIndexes()
 				mock.noForeignKeys()
@@ -138,9 +216,10 @@ func TestInspectTable(t *testing.T) {
 				}
 				tables := []*schema.Table{
 					{
-						Name:    tableName,
-						Comment: "Comment",
-						Schema:  s,
+						Name:      tableName,
+						Comment:   "Comment",
+						Namespace: schemaName,
+						Schema:    s,
 					},
 				}
 				columns := []*schema.Column{
@@ -199,26 +278,61 @@ func TestInspectTable(t *testing.T) {
 
 			},
 		},
+		{
+			name: "auto increment and generated",
+			before: func(mock postgresMock) {
+				mock.info()
+				mock.ExpectQuery(Escape(TablesQuery)).
+					WillReturnRows(sqlmock.NewRows(TablesQueryFields).
+						AddRow(schemaName, tableName, "Comment"))
+
+				// ..., is_identity, is_generated, on_update
+				mock.ExpectQuery(Escape(ColumnsQuery)).
+					WithArgs(schemaName).
+					WillReturnRows(sqlmock.NewRows(ColumnsQueryFields).
+						AddRow(schemaName, tableName, "id", "bigint", nil, "NO", "nextval('table_id_seq'::regclass)", nil, nil, 64, 0, nil, "int8", "b", 1, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "seq", "integer", nil, "NO", nil, nil, nil, 32, 0, nil, "int4", "b", 2, "YES", "NEVER", false).
+						AddRow(schemaName, tableName, "total", "integer", nil, "YES", nil, nil, nil, 32, 0, nil, "int4", "b", 3, "NO", "ALWAYS", false).
+						AddRow(schemaName, tableName, "updated_at", "timestamp with time zone", nil, "NO", "CURRENT_TIMESTAMP", nil, nil, nil, nil, nil, "timestamptz", "b", 4, "NO", "NEVER", true))
+
+				mock.noIndexes()
+				mock.noForeignKeys()
+			},
+			expected: func() *schema.Schema {
+				s := &schema.Schema{Name: schemaName}
+				table := &schema.Table{Name: tableName, Comment: "Comment", Namespace: schemaName, Schema: s}
+				table.Columns = []*schema.Column{
+					{Name: "id", Type: &spec.IntegerType{Name: "bigint", Size: 64}, AutoIncrement: true, Table: table},
+					{Name: "seq", Type: &spec.IntegerType{Name: "integer", Size: 32}, AutoIncrement: true, Table: table},
+					{Name: "total", Type: &spec.IntegerType{Name: "integer", Size: 32}, Nullable: true, Generated: true, Table: table},
+					{Name: "updated_at", Type: &spec.TimeType{Name: "timestamp with time zone"}, Default: sql.NullString{String: "CURRENT_TIMESTAMP", Valid: true}, OnUpdate: true, Table: table},
+				}
+				s.Tables = []*schema.Table{table}
+				return s
+			},
+		},
 		{
 			name: "indexes",
 			before: func(mock postgresMock) {
 				mock.info()
 				mock.ExpectQuery(Escape(TablesQuery)).
-					WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "COMMENT"}).
-						AddRow(tableName, "Comment"))
+					WillReturnRows(sqlmock.NewRows(TablesQueryFields).
+						AddRow(schemaName, tableName, "Comment"))
 
 				mock.noColumns()
 
 				mock.ExpectQuery(Escape(IndexesQuery)).
-					WithArgs(tableName).
+					WithArgs(schemaName).
 					WillReturnRows(sqlmock.NewRows(IndexesQueryFields).
-						AddRow("bigint", "btree", "bigint", 1, 0, "p", nil, nil, 0, 0, 0, 0, "comment").
-						AddRow("character_idx", "btree", "character", 0, 0, "u", nil, nil, 0, 0, 0, 0, nil).
-						AddRow("subpart", "btree", "character1", 0, 0, nil, nil, nil, 0, 0, 0, 0, nil).
-						AddRow("non_unique", "btree", "char", 0, 0, nil, nil, nil, 0, 0, 0, 0, nil).
-						AddRow("unique", "btree", "char", 0, 0, nil, nil, nil, 0, 0, 0, 0, nil).
-						AddRow("unique_union", "btree", "char", 0, 0, nil, nil, nil, 0, 0, 0, 0, nil).
-						AddRow("unique_union", "btree", "character1", 0, 0, nil, nil, nil, 0, 0, 0, 0, nil))
+						AddRow(schemaName, tableName, "bigint", "btree", "bigint", 1, 0, "p", nil, nil, 0, 0, 0, 0, "comment").
+						AddRow(schemaName, tableName, "character_idx", "btree", "character", 0, 0, "u", nil, nil, 0, 0, 0, 0, nil).
+						AddRow(schemaName, tableName, "subpart", "btree", "character1", 0, 0, nil, nil, nil, 0, 0, 0, 0, nil).
+						AddRow(schemaName, tableName, "non_unique", "btree", "char", 0, 0, nil, nil, nil, 0, 0, 0, 0, nil).
+						AddRow(schemaName, tableName, "unique", "btree", "char", 0, 0, nil, nil, nil, 0, 0, 0, 0, nil).
+						AddRow(schemaName, tableName, "unique_union", "btree", "char", 0, 0, nil, nil, nil, 0, 0, 0, 0, nil).
+						AddRow(schemaName, tableName, "unique_union", "btree", "character1", 0, 0, nil, nil, nil, 0, 0, 0, 0, nil).
+						AddRow(schemaName, tableName, "lower_idx", "btree", nil, 0, 0, nil, nil, "lower(\"character\")", 0, 0, 0, 0, nil).
+						AddRow(schemaName, tableName, "lower_idx", "btree", "char", 0, 0, nil, nil, nil, 0, 0, 0, 0, nil))
 
 				mock.noForeignKeys()
 
@@ -229,9 +343,10 @@ func TestInspectTable(t *testing.T) {
 				}
 
 				table := &schema.Table{
-					Name:    tableName,
-					Comment: "Comment",
-					Schema:  s,
+					Name:      tableName,
+					Comment:   "Comment",
+					Namespace: schemaName,
+					Schema:    s,
 				}
 
 				tables := []*schema.Table{
@@ -319,6 +434,14 @@ func TestInspectTable(t *testing.T) {
 						},
 						Type: "btree",
 					},
+					{
+						Name: "lower_idx",
+						IndexColumns: []*schema.IndexColumn{
+							{SeqNo: 1, Expr: "lower(\"character\")"},
+							{SeqNo: 2, Column: "char"},
+						},
+						Type: "btree",
+					},
 				}
 
 				table.Indexes = indexes
@@ -332,38 +455,29 @@ func TestInspectTable(t *testing.T) {
 			before: func(mock postgresMock) {
 				mock.info()
 				mock.ExpectQuery(Escape(TablesQuery)).
-					WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "COMMENT"}).
-						AddRow(tableName, "Comment").
-						AddRow(fkTableName, "Comment"))
-
-				mock.ExpectQuery(Escape(ColumnsQuery)).
-					WithArgs(tableName).
-					WillReturnRows(sqlmock.NewRows(ColumnsQueryFields).
-						AddRow("id", "bigint", "id comment", "NO", nil, nil, nil, 64, 0, nil, "int8", "b", 1).
-						AddRow("gid", "bigint", "gid comment", "NO", nil, nil, nil, 64, 0, nil, "int8", "b", 1).
-						AddRow("cid", "bigint", "cid comment", "NO", nil, nil, nil, 64, 0, nil, "int8", "b", 1).
-						AddRow("uid", "bigint", "uid comment", "NO", nil, nil, nil, 64, 0, nil, "int8", "b", 1))
+					WillReturnRows(sqlmock.NewRows(TablesQueryFields).
+						AddRow(schemaName, tableName, "Comment").
+						AddRow(schemaName, fkTableName, "Comment"))
 
 				mock.ExpectQuery(Escape(ColumnsQuery)).
-					WithArgs(fkTableName).
+					WithArgs(schemaName).
 					WillReturnRows(sqlmock.NewRows(ColumnsQueryFields).
-						AddRow("id", "bigint", "id comment", "NO", nil, nil, nil, 64, 0, nil, "int8", "b", 1).
-						AddRow("cid", "bigint", "cid comment", "NO", nil, nil, nil, 64, 0, nil, "int8", "b", 1))
+						AddRow(schemaName, tableName, "id", "bigint", "id comment", "NO", nil, nil, nil, 64, 0, nil, "int8", "b", 1, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "gid", "bigint", "gid comment", "NO", nil, nil, nil, 64, 0, nil, "int8", "b", 1, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "cid", "bigint", "cid comment", "NO", nil, nil, nil, 64, 0, nil, "int8", "b", 1, "NO", "NEVER", false).
+						AddRow(schemaName, tableName, "uid", "bigint", "uid comment", "NO", nil, nil, nil, 64, 0, nil, "int8", "b", 1, "NO", "NEVER", false).
+						AddRow(schemaName, fkTableName, "id", "bigint", "id comment", "NO", nil, nil, nil, 64, 0, nil, "int8", "b", 1, "NO", "NEVER", false).
+						AddRow(schemaName, fkTableName, "cid", "bigint", "cid comment", "NO", nil, nil, nil, 64, 0, nil, "int8", "b", 1, "NO", "NEVER", false))
 
 				mock.noIndexes()
-				mock.noIndexes()
 
 				mock.ExpectQuery(Escape(ForeignKeysQuery)).
-					WithArgs(tableName).
+					WithArgs(schemaName).
 					WillReturnRows(sqlmock.NewRows(ForeignKeysQueryFields).
 						AddRow("multi_column", tableName, "gid", schemaName, fkTableName, "id", schemaName, "NO ACTION", "CASCADE").
 						AddRow("multi_column", tableName, "cid", schemaName, fkTableName, "cid", schemaName, "NO ACTION", "CASCADE").
 						AddRow("self_reference", tableName, "uid", schemaName, tableName, "id", schemaName, "NO ACTION", "CASCADE"))
 
-				mock.ExpectQuery(Escape(ForeignKeysQuery)).
-					WithArgs(fkTableName).
-					WillReturnRows(sqlmock.NewRows(ForeignKeysQueryFields))
-
 			},
 			expected: func() *schema.Schema {
 				s := &schema.Schema{
@@ -371,15 +485,17 @@ func TestInspectTable(t *testing.T) {
 				}
 
 				table := &schema.Table{
-					Name:    tableName,
-					Comment: "Comment",
-					Schema:  s,
+					Name:      tableName,
+					Comment:   "Comment",
+					Namespace: schemaName,
+					Schema:    s,
 				}
 
 				tableFK := &schema.Table{
-					Name:    fkTableName,
-					Comment: "Comment",
-					Schema:  s,
+					Name:      fkTableName,
+					Comment:   "Comment",
+					Namespace: schemaName,
+					Schema:    s,
 				}
 
 				columns := []*schema.Column{
@@ -458,6 +574,118 @@ func TestInspectTable(t *testing.T) {
 				return s
 			},
 		},
+		{
+			name:    "multiple schemas",
+			schemas: []string{"billing", "auth"},
+			before: func(mock postgresMock) {
+				mock.info()
+				mock.ExpectQuery(Escape(TablesQuery)).
+					WithArgs("billing,auth").
+					WillReturnRows(sqlmock.NewRows(TablesQueryFields).
+						AddRow("billing", "invoice", nil).
+						AddRow("auth", "user", nil))
+
+				mock.ExpectQuery(Escape(ColumnsQuery)).
+					WithArgs("billing,auth").
+					WillReturnRows(sqlmock.NewRows(ColumnsQueryFields).
+						AddRow("billing", "invoice", "id", "bigint", nil, "NO", nil, nil, nil, 64, 0, nil, "int8", "b", 1, "NO", "NEVER", false).
+						AddRow("billing", "invoice", "user_id", "bigint", nil, "NO", nil, nil, nil, 64, 0, nil, "int8", "b", 1, "NO", "NEVER", false).
+						AddRow("auth", "user", "id", "bigint", nil, "NO", nil, nil, nil, 64, 0, nil, "int8", "b", 1, "NO", "NEVER", false))
+
+				mock.noIndexes()
+
+				// 跨 schema 外键: billing.invoice.user_id => auth.user.id
+				mock.ExpectQuery(Escape(ForeignKeysQuery)).
+					WithArgs("billing,auth").
+					WillReturnRows(sqlmock.NewRows(ForeignKeysQueryFields).
+						AddRow("invoice_user_id_fkey", "invoice", "user_id", "billing", "user", "id", "auth", "NO ACTION", "RESTRICT"))
+			},
+			expected: func() *schema.Schema {
+				s := &schema.Schema{Name: schemaName}
+				invoice := &schema.Table{Name: "invoice", Namespace: "billing", Schema: s}
+				user := &schema.Table{Name: "user", Namespace: "auth", Schema: s}
+				invoice.Columns = []*schema.Column{
+					{Name: "id", Type: &spec.IntegerType{Name: "bigint", Size: 64}, Table: invoice},
+					{Name: "user_id", Type: &spec.IntegerType{Name: "bigint", Size: 64}, Table: invoice},
+				}
+				user.Columns = []*schema.Column{
+					{Name: "id", Type: &spec.IntegerType{Name: "bigint", Size: 64}, Table: user},
+				}
+				invoice.ForeignKeys = []*schema.ForeignKey{
+					{
+						Name:       "invoice_user_id_fkey",
+						Table:      invoice,
+						Columns:    invoice.Columns[1:],
+						RefTable:   user,
+						RefColumns: user.Columns,
+						OnUpdate:   schema.NoAction,
+						OnDelete:   schema.Restrict,
+					},
+				}
+				s.Tables = []*schema.Table{invoice, user}
+				return s
+			},
+		},
+		{
+			// 不同 schema 中的同名枚举类型，按 udt_schema 区分
+			name:    "same enum name in schemas",
+			schemas: []string{"billing", "shop"},
+			before: func(mock postgresMock) {
+				mock.info()
+				mock.ExpectQuery(Escape(TablesQuery)).
+					WithArgs("billing,shop").
+					WillReturnRows(sqlmock.NewRows(TablesQueryFields).
+						AddRow("billing", "order", nil).
+						AddRow("shop", "order", nil))
+				mock.ExpectQuery(Escape(ColumnsQuery)).
+					WithArgs("billing,shop").
+					WillReturnRows(sqlmock.NewRows(ColumnsQueryFields).
+						AddRow("billing", "order", "status", "USER-DEFINED", nil, "NO", nil, nil, nil, nil, nil, nil, "status", "e", 100, "NO", "NEVER", false).
+						AddRow("shop", "order", "status", "USER-DEFINED", nil, "NO", nil, nil, nil, nil, nil, nil, "status", "e", 200, "NO", "NEVER", false))
+				mock.ExpectQuery(Escape(EnumQuery)).
+					WithArgs("100,200").
+					WillReturnRows(sqlmock.NewRows(EnumQueryFields).
+						AddRow(100, "unpaid").AddRow(100, "paid").
+						AddRow(200, "cart").AddRow(200, "shipped"))
+				mock.noIndexes()
+				mock.noForeignKeys()
+			},
+			expected: func() *schema.Schema {
+				s := &schema.Schema{Name: schemaName}
+				billing := &schema.Table{Name: "order", Namespace: "billing", Schema: s}
+				shop := &schema.Table{Name: "order", Namespace: "shop", Schema: s}
+				billing.Columns = []*schema.Column{
+					{Name: "status", Type: &spec.EnumType{Name: "status", Values: []string{"unpaid", "paid"}}, Table: billing},
+				}
+				shop.Columns = []*schema.Column{
+					{Name: "status", Type: &spec.EnumType{Name: "status", Values: []string{"cart", "shipped"}}, Table: shop},
+				}
+				s.Tables = []*schema.Table{billing, shop}
+				return s
+			},
+		},
+		{
+			name:    "ref schema not loaded",
+			schemas: []string{"billing"},
+			before: func(mock postgresMock) {
+				mock.info()
+				mock.ExpectQuery(Escape(TablesQuery)).
+					WithArgs("billing").
+					WillReturnRows(sqlmock.NewRows(TablesQueryFields).
+						AddRow("billing", "invoice", nil))
+				mock.ExpectQuery(Escape(ColumnsQuery)).
+					WithArgs("billing").
+					WillReturnRows(sqlmock.NewRows(ColumnsQueryFields).
+						AddRow("billing", "invoice", "user_id", "bigint", nil, "NO", nil, nil, nil, 64, 0, nil, "int8", "b", 1, "NO", "NEVER", false))
+				mock.noIndexes()
+				mock.ExpectQuery(Escape(ForeignKeysQuery)).
+					WithArgs("billing").
+					WillReturnRows(sqlmock.NewRows(ForeignKeysQueryFields).
+						AddRow("invoice_user_id_fkey", "invoice", "user_id", "billing", "user", "id", "auth", "NO ACTION", "RESTRICT"))
+			},
+			expected: func() *schema.Schema { return nil },
+			wantErr:  true,
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -465,7 +693,8 @@ func TestInspectTable(t *testing.T) {
 			require.NoError(t, err)
 			test.before(postgresMock{mock})
 			l := &inspect{
-				Driver: schema.OpenDB(cre.MySQL, db),
+				Driver:  schema.OpenDB(cre.MySQL, db),
+				schemas: test.schemas,
 			}
 
 			require.NoError(t, err)
@@ -1,7 +1,9 @@
 package postgres
 
 import (
+	"database/sql"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/ychengcloud/cre/spec"
@@ -74,3 +76,111 @@ func parseType(ci *columnInfo) (spec.Type, error) {
 	}
 
 }
+
+// typeAliases maps the type names used in DDL onto the data_type reported by information_schema.
+var typeAliases = map[string]string{
+	TypeInt:         TypeInteger,
+	TypeInt2:        TypeSmallInt,
+	TypeInt4:        TypeInteger,
+	TypeInt8:        TypeBigInt,
+	TypeSmallSerial: TypeSmallInt,
+	TypeSerial2:     TypeSmallInt,
+	TypeSerial:      TypeInteger,
+	TypeSerial4:     TypeInteger,
+	TypeBigSerial:   TypeBigInt,
+	TypeSerial8:     TypeBigInt,
+	TypeChar:        TypeCharacter,
+	"bpchar":        TypeCharacter,
+	TypeVarChar:     TypeCharVar,
+	"varbit":        TypeBitVar,
+	TypeBool:        TypeBoolean,
+	TypeDecimal:     TypeNumeric,
+	TypeFloat4:      TypeReal,
+	TypeFloat8:      TypeDouble,
+	TypeTimestamp:   TypeTimestampWithoutTZ,
+	TypeTimestampTZ: TypeTimestampWithTZ,
+	TypeTime:        TypeTimeWithoutTZ,
+	"timetz":        TypeTimeWithTZ,
+}
+
+// parseColumnDef parses the column type written in DDL into the column info,
+// the same as the one inspected from information_schema.
+// eg: varchar(255) => character varying, size 255
+//
+//	timestamp(6) with time zone => timestamp with time zone
+func parseColumnDef(colDef string) (*columnInfo, error) {
+	colDef = strings.ToLower(strings.TrimSpace(colDef))
+	ci := &columnInfo{}
+
+	// integer[], integer ARRAY
+	if strings.HasSuffix(colDef, "]") || strings.HasSuffix(colDef, " array") {
+		ci.dataType = TypeArray
+		return ci, nil
+	}
+
+	var args []int64
+	if before, after, found := strings.Cut(colDef, "("); found {
+		inner, rest, _ := strings.Cut(after, ")")
+		for _, arg := range strings.FieldsFunc(inner, func(c rune) bool { return c == ',' || c == ' ' }) {
+			n, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid type argument: %v", arg)
+			}
+			args = append(args, n)
+		}
+		colDef = before + " " + rest
+	}
+
+	dt := strings.Join(strings.Fields(colDef), " ")
+	if alias, ok := typeAliases[dt]; ok {
+		dt = alias
+	}
+	// float(p): p <= 24 为 real, 否则为 double precision
+	if dt == "float" {
+		dt = TypeDouble
+		if len(args) > 0 && args[0] <= 24 {
+			dt = TypeReal
+		}
+		args = nil
+	}
+	ci.dataType = dt
+
+	switch dt {
+	case TypeCharacter, TypeBit:
+		ci.size = 1
+		if len(args) > 0 {
+			ci.size = args[0]
+		}
+	case TypeCharVar, TypeBitVar:
+		if len(args) > 0 {
+			ci.size = args[0]
+		}
+	case TypeNumeric:
+		if len(args) > 0 {
+			ci.precision = args[0]
+		}
+		if len(args) > 1 {
+			ci.scale = args[1]
+		}
+	case TypeReal:
+		ci.precision = 24
+	case TypeDouble:
+		ci.precision = 53
+	}
+	return ci, nil
+}
+
+// ParseType parses the column type written in DDL, eg: varchar(255), numeric(10,2).
+// Enum types are user defined and can not be resolved here.
+func ParseType(colDef string) (spec.Type, error) {
+	ci, err := parseColumnDef(colDef)
+	if err != nil {
+		return nil, err
+	}
+	return parseType(ci)
+}
+
+// isSerial returns true if the column default is generated by a sequence.
+func isSerial(defaults sql.NullString) bool {
+	return defaults.Valid && strings.HasPrefix(strings.ToLower(defaults.String), "nextval(")
+}
@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ychengcloud/cre/spec"
+)
+
+func TestParseType(t *testing.T) {
+	tests := []struct {
+		colDef   string
+		expected spec.Type
+		wantErr  bool
+	}{
+		{colDef: "smallint", expected: &spec.IntegerType{Name: "smallint", Size: 16}},
+		{colDef: "INT", expected: &spec.IntegerType{Name: "integer", Size: 32}},
+		{colDef: "int8", expected: &spec.IntegerType{Name: "bigint", Size: 64}},
+		{colDef: "bigserial", expected: &spec.IntegerType{Name: "bigint", Size: 64}},
+		{colDef: "bool", expected: &spec.BoolType{Name: "boolean"}},
+		{colDef: "numeric(10, 2)", expected: &spec.FloatType{Name: "numeric", Precision: 10, Scale: 2}},
+		{colDef: "decimal", expected: &spec.FloatType{Name: "numeric"}},
+		{colDef: "real", expected: &spec.FloatType{Name: "real", Precision: 24}},
+		{colDef: "float(10)", expected: &spec.FloatType{Name: "real", Precision: 24}},
+		{colDef: "float", expected: &spec.FloatType{Name: "double precision", Precision: 53}},
+		{colDef: "double precision", expected: &spec.FloatType{Name: "double precision", Precision: 53}},
+		{colDef: "char", expected: &spec.StringType{Name: "character", Size: 1}},
+		{colDef: "varchar(255)", expected: &spec.StringType{Name: "character varying", Size: 255}},
+		{colDef: "character varying", expected: &spec.StringType{Name: "character varying"}},
+		{colDef: "bit", expected: &spec.BitType{Name: "bit", Len: 1}},
+		{colDef: "varbit(8)", expected: &spec.BitType{Name: "bit varying", Len: 8}},
+		{colDef: "timestamptz", expected: &spec.TimeType{Name: "timestamp with time zone"}},
+		{colDef: "timestamp(6) without time zone", expected: &spec.TimeType{Name: "timestamp without time zone"}},
+		{colDef: "time", expected: &spec.TimeType{Name: "time without time zone"}},
+		{colDef: "jsonb", expected: &spec.JSONType{Name: "jsonb"}},
+		{colDef: "integer[]", expected: &spec.SpatialType{Name: "array"}},
+		{colDef: "varchar(abc)", wantErr: true},
+		{colDef: "unknown", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.colDef, func(t *testing.T) {
+			actual, err := ParseType(test.colDef)
+			require.Equal(t, test.wantErr, err != nil, err)
+			require.Equal(t, test.expected, actual)
+		})
+	}
+}
@@ -85,21 +85,39 @@ var (
 	VariablesQuery = `SELECT setting FROM pg_settings WHERE name IN ('lc_collate', 'lc_ctype', 'server_version_num') ORDER BY name`
 
 	// TableQuery 表查询语句
+	// $1 为逗号分隔的 schema 列表，为空时使用 CURRENT_SCHEMA()，结果按 schema 在列表中的顺序排列
+	TablesQueryFields = []string{
+		"table_schema",
+		"table_name",
+		"comment",
+	}
+
 	TablesQuery = `
-SELECT 
-	t1.TABLE_NAME, 
-	pg_catalog.obj_description(t2.oid, 'pg_class') AS COMMENT 
-FROM 
-	INFORMATION_SCHEMA.TABLES AS t1 
-	JOIN pg_catalog.pg_class AS t2 
-	ON t1.table_name = t2.relname 
-WHERE 
-	t1.TABLE_TYPE = 'BASE TABLE' 
-	AND t1.table_schema = (CURRENT_SCHEMA())
+WITH ns AS (
+	SELECT string_to_array(COALESCE(NULLIF($1, ''), CURRENT_SCHEMA()), ',') AS names
+)
+SELECT
+	t1.table_schema,
+	t1.table_name,
+	pg_catalog.obj_description(t2.oid, 'pg_class') AS comment
+FROM
+	INFORMATION_SCHEMA.TABLES AS t1
+	JOIN pg_catalog.pg_class AS t2
+	ON t2.oid = to_regclass(quote_ident(t1.table_schema) || '.' || quote_ident(t1.table_name))
+	CROSS JOIN ns
+WHERE
+	t1.TABLE_TYPE = 'BASE TABLE'
+	AND t1.table_schema = ANY(ns.names)
+ORDER BY
+	array_position(ns.names, t1.table_schema::text)
 `
 
-	// ColumnsQuery 列查询语句
+	// ColumnsQuery 列查询语句，$1 为逗号分隔的 schema 列表，一次查询所有表的列
+	// on_update: 存在 BEFORE UPDATE 行级触发器将该列设置为当前时间(NEW.col := now())
+	// tgtype: ROW(1) | BEFORE(2) | UPDATE(16)
 	ColumnsQueryFields = []string{
+		"table_schema",
+		"table_name",
 		"column_name",
 		"data_type",
 		"comment",
@@ -113,10 +131,15 @@ WHERE
 		"udt_name",
 		"typtype",
 		"oid",
+		"is_identity",
+		"is_generated",
+		"on_update",
 	}
 
 	ColumnsQuery = `
 SELECT
+	t1.table_schema,
+	t1.table_name,
 	t1.column_name,
 	t1.data_type,
 	col_description(to_regclass("table_schema" || '.' || "table_name")::oid, "ordinal_position") AS comment,
@@ -129,21 +152,47 @@ SELECT
 	t1.character_maximum_length,
 	t1.udt_name,
 	t2.typtype,
-	t2.oid
+	t2.oid,
+	t1.is_identity,
+	t1.is_generated,
+	EXISTS (
+		SELECT
+			1
+		FROM
+			pg_trigger AS tg
+			JOIN pg_proc AS p
+			ON p.oid = tg.tgfoid
+		WHERE
+			tg.tgrelid = to_regclass(quote_ident(t1.table_schema) || '.' || quote_ident(t1.table_name))::oid
+			AND NOT tg.tgisinternal
+			AND (tg.tgtype & 19) = 19
+			AND p.prosrc ~* ('new\.' || quote_ident(t1.column_name) || '\s*:?=\s*(now\(\)|current_timestamp|clock_timestamp\(\)|localtimestamp|statement_timestamp\(\)|transaction_timestamp\(\))')
+	) AS on_update
 FROM
 	"information_schema"."columns" AS t1
+	LEFT JOIN pg_catalog.pg_namespace AS t3
+	ON t1.udt_schema = t3.nspname
 	LEFT JOIN pg_catalog.pg_type AS t2
 	ON t1.udt_name = t2.typname
+	AND t2.typnamespace = t3.oid
 WHERE
 	t2.typtype != 'c'
-	AND t1.TABLE_NAME = $1
-	AND t1.table_schema = (CURRENT_SCHEMA())
+	AND t1.table_schema = ANY(string_to_array($1, ','))
+ORDER BY
+	t1.table_schema,
+	t1.table_name,
+	t1.ordinal_position
 `
 
-	EnumQuery = "SELECT enumlabel FROM pg_enum WHERE enumtypid = $1"
+	// EnumQuery 枚举值查询语句，$1 为逗号分隔的枚举类型 oid 列表
+	EnumQueryFields = []string{"enumtypid", "enumlabel"}
+	EnumQuery       = "SELECT enumtypid, enumlabel FROM pg_enum WHERE enumtypid = ANY(string_to_array($1, ',')::oid[]) ORDER BY enumtypid, enumsortorder"
 
-	// IndexesQuery 索引查询语句
+	// IndexesQuery 索引查询语句，$1 为逗号分隔的 schema 列表
+	// 表达式索引中 a.attname 为索引关系上生成的名称(如 lower)，此时 column_name 为空，expression 为该列的表达式
 	IndexesQueryFields = []string{
+		"table_schema",
+		"table_name",
 		"index_name",
 		"index_type",
 		"column_name",
@@ -160,23 +209,29 @@ WHERE
 	}
 	IndexesQuery = `
 SELECT
+	n.nspname AS table_schema,
+	t.relname AS table_name,
 	i.relname AS index_name,
 	am.amname AS index_type,
-	a.attname AS column_name,
+	CASE WHEN idx.indkey[a.attnum - 1] = 0 THEN NULL ELSE a.attname END AS column_name,
 	idx.indisprimary AS primary,
 	idx.indisunique AS unique,
 	c.contype AS constraint_type,
 	pg_get_expr(idx.indpred, idx.indrelid) AS predicate,
-	pg_get_expr(idx.indexprs, idx.indrelid) AS expression,
+	CASE WHEN idx.indkey[a.attnum - 1] = 0 THEN pg_get_indexdef(idx.indexrelid, a.attnum, true) END AS expression,
 	pg_index_column_has_property(idx.indexrelid, a.attnum, 'asc') AS asc,
 	pg_index_column_has_property(idx.indexrelid, a.attnum, 'desc') AS desc,
 	pg_index_column_has_property(idx.indexrelid, a.attnum, 'nulls_first') AS nulls_first,
 	pg_index_column_has_property(idx.indexrelid, a.attnum, 'nulls_last') AS nulls_last,
-	obj_description(to_regclass(CURRENT_SCHEMA() || i.relname)::oid) AS comment
+	obj_description(i.oid, 'pg_class') AS comment
 FROM
 	pg_index idx
 	JOIN pg_class i
 	ON i.oid = idx.indexrelid
+	JOIN pg_class t
+	ON t.oid = idx.indrelid
+	JOIN pg_namespace n
+	ON n.oid = t.relnamespace
 	LEFT JOIN pg_constraint c
 	ON idx.indexrelid = c.conindid
 	LEFT JOIN pg_attribute a
@@ -184,13 +239,13 @@ FROM
 	JOIN pg_am am
 	ON am.oid = i.relam
 WHERE
-	idx.indrelid = to_regclass(CURRENT_SCHEMA() || '.' || $1)::oid
+	n.nspname = ANY(string_to_array($1, ','))
 	AND COALESCE(c.contype, '') <> 'f'
 ORDER BY
-	index_name, a.attnum
+	table_schema, table_name, index_name, a.attnum
 `
 
-	// ForeignKeysQuery 外键查询语句
+	// ForeignKeysQuery 外键查询语句，$1 为逗号分隔的 schema 列表
 	ForeignKeysQueryFields = []string{
 		"constraint_name",
 		"table_name",
@@ -202,33 +257,56 @@ ORDER BY
 		"update_rule",
 		"delete_rule",
 	}
+
+	// 引用动作取自 pg_constraint(confupdtype/confdeltype)，conkey 与 confkey 按位置一一对应
+	// 被引用表可以位于其他 schema，需同时被加载
 	ForeignKeysQuery = `
 SELECT
-	t1.constraint_name,
-	t1.table_name,
-	t2.column_name,
-	t1.table_schema,
-	t3.table_name AS referenced_table_name,
-	t3.column_name AS referenced_column_name,
-	t3.table_schema AS referenced_schema_name,
-	t4.update_rule,
-	t4.delete_rule
+	con.conname AS constraint_name,
+	t.relname AS table_name,
+	a.attname AS column_name,
+	ns.nspname AS table_schema,
+	rt.relname AS referenced_table_name,
+	ra.attname AS referenced_column_name,
+	rns.nspname AS referenced_schema_name,
+	CASE con.confupdtype
+		WHEN 'r' THEN 'RESTRICT'
+		WHEN 'c' THEN 'CASCADE'
+		WHEN 'n' THEN 'SET NULL'
+		WHEN 'd' THEN 'SET DEFAULT'
+		ELSE 'NO ACTION'
+	END AS update_rule,
+	CASE con.confdeltype
+		WHEN 'r' THEN 'RESTRICT'
+		WHEN 'c' THEN 'CASCADE'
+		WHEN 'n' THEN 'SET NULL'
+		WHEN 'd' THEN 'SET DEFAULT'
+		ELSE 'NO ACTION'
+	END AS delete_rule
 FROM
-	information_schema.table_constraints t1
-	JOIN information_schema.key_column_usage t2
-	ON t1.constraint_name = t2.constraint_name
-	AND t1.table_schema = t2.constraint_schema
-	JOIN information_schema.constraint_column_usage t3
-	ON t1.constraint_name = t3.constraint_name
-	AND t1.table_schema = t3.constraint_schema
-	JOIN information_schema.referential_constraints t4
-	ON t1.constraint_name = t4.constraint_name
-	AND t1.table_schema = t4.constraint_schema
+	pg_constraint con
+	JOIN pg_class t
+	ON t.oid = con.conrelid
+	JOIN pg_namespace ns
+	ON ns.oid = t.relnamespace
+	JOIN pg_class rt
+	ON rt.oid = con.confrelid
+	JOIN pg_namespace rns
+	ON rns.oid = rt.relnamespace
+	CROSS JOIN LATERAL unnest(con.conkey, con.confkey) WITH ORDINALITY AS k(attnum, refattnum, seq)
+	JOIN pg_attribute a
+	ON a.attrelid = con.conrelid
+	AND a.attnum = k.attnum
+	JOIN pg_attribute ra
+	ON ra.attrelid = con.confrelid
+	AND ra.attnum = k.refattnum
 WHERE
-	t1.constraint_type = 'FOREIGN KEY'
-	AND t1.table_name = $1
+	con.contype = 'f'
+	AND ns.nspname = ANY(string_to_array($1, ','))
 ORDER BY
-	t1.constraint_name,
-	t2.ordinal_position
+	ns.nspname,
+	t.relname,
+	con.conname,
+	k.seq
 `
 )
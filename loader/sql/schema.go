@@ -46,6 +46,9 @@ type (
 		ForeignKeys   []*ForeignKey
 		Attrs         []Attribute
 
+		// Namespace 表所在的数据库 schema，仅在 Postgres 中使用
+		Namespace string
+
 		Schema *Schema
 	}
 
@@ -65,6 +68,7 @@ type (
 		Primary       bool
 		AutoIncrement bool
 		OnUpdate      bool
+		Generated     bool // Generated 生成列，值由表达式计算
 		Attrs         []Attribute
 
 		Table *Table
@@ -101,13 +105,23 @@ type (
 	}
 )
 
+// Table returns the table with the given name, 支持 schema 限定名称，如 billing.invoice
 func (s *Schema) Table(name string) *Table {
+	var found *Table
 	for _, t := range s.Tables {
-		if t.Name == name {
+		if t.QualifiedName() == name {
 			return t
 		}
+		if found == nil && t.Name == name {
+			found = t
+		}
 	}
-	return nil
+	return found
+}
+
+// QualifiedName returns the table name with the namespace prefix.
+func (t *Table) QualifiedName() string {
+	return spec.QualifiedName(t.Namespace, t.Name)
 }
 
 func (t *Table) Column(name string) *Column {
@@ -0,0 +1,349 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ychengcloud/cre"
+	schema "github.com/ychengcloud/cre/loader/sql"
+	"github.com/ychengcloud/cre/spec"
+)
+
+type inspect struct {
+	Driver cre.Driver
+
+	schema *schema.Schema
+	// primaryKeys 按主键中的顺序(pragma_table_info.pk)排列的主键列，与列的声明顺序可能不同
+	primaryKeys map[*schema.Table][]*schema.Column
+
+	// Database information
+	version string
+}
+
+var _ schema.Inspector = (*inspect)(nil)
+
+func NewInspector(drv cre.Driver) schema.Inspector {
+	return &inspect{Driver: drv}
+}
+func (i *inspect) Inspect(ctx context.Context, name string) (*schema.Schema, error) {
+	i.schema = &schema.Schema{Name: name}
+	i.primaryKeys = make(map[*schema.Table][]*schema.Column)
+
+	err := i.dbInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tables, err := i.tables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	i.schema.Tables = tables
+
+	err = i.inspectColumns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.inspectIndexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.inspectForeignKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return i.schema, nil
+}
+
+func (i *inspect) dbInfo(ctx context.Context) error {
+	row, err := i.querySqlRow(ctx, VersionQuery)
+	if err != nil {
+		return err
+	}
+
+	if err := row.Scan(&i.version); err != nil {
+		return fmt.Errorf("sqlite/dbInfo: scan fail: %w", err)
+	}
+	return nil
+}
+
+func (i *inspect) tables(ctx context.Context) ([]*schema.Table, error) {
+	rows, err := i.querySqlRows(ctx, TablesQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []*schema.Table
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("sqlite/tables: scan [%w]", err)
+		}
+
+		table := &schema.Table{
+			Name:   name,
+			Schema: i.schema,
+		}
+		tables = append(tables, table)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tables, nil
+}
+
+func (i *inspect) inspectColumns(ctx context.Context) error {
+	for _, table := range i.schema.Tables {
+		columns, err := i.columns(ctx, table)
+		if err != nil {
+			return err
+		}
+		table.Columns = columns
+	}
+	return nil
+}
+
+func (i *inspect) inspectIndexes(ctx context.Context) error {
+	for _, table := range i.schema.Tables {
+		indexes, err := i.indexes(ctx, table.Name)
+		if err != nil {
+			return err
+		}
+		table.Indexes = indexes
+	}
+	return nil
+}
+
+func (i *inspect) inspectForeignKeys(ctx context.Context) error {
+	for _, table := range i.schema.Tables {
+		foreignKeys, err := i.foreignKeys(ctx, table)
+		if err != nil {
+			return err
+		}
+		table.ForeignKeys = foreignKeys
+	}
+	return nil
+}
+
+func (i *inspect) columns(ctx context.Context, t *schema.Table) ([]*schema.Column, error) {
+	rows, err := i.querySqlRows(ctx, ColumnsQuery, t.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var (
+		columns []*schema.Column
+		pks     []*schema.Column
+		seqs    = make(map[*schema.Column]int)
+	)
+	for rows.Next() {
+		var (
+			cid, notnull, pk int
+			name, colType    string
+			defaults         sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notnull, &defaults, &pk); err != nil {
+			return nil, fmt.Errorf("sqlite/columns: scan [%w]", err)
+		}
+
+		ct, err := ParseType(colType)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite/columns: parse type [%s, %w]", t.Name, err)
+		}
+
+		column := &schema.Column{
+			Name:     name,
+			Type:     ct,
+			Nullable: notnull == 0,
+			Default:  defaults,
+			Primary:  pk > 0,
+			Table:    t,
+		}
+
+		switch ct := column.Type.(type) {
+		case *spec.FloatType:
+			column.Precision = ct.Precision
+			column.Scale = ct.Scale
+		}
+
+		if column.Primary {
+			pks = append(pks, column)
+			seqs[column] = pk
+		}
+		columns = append(columns, column)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// pk 为列在主键中的位置(从 1 开始)，PRIMARY KEY(a, b) 中列的声明顺序可能为 b, a
+	sort.SliceStable(pks, func(m, n int) bool { return seqs[pks[m]] < seqs[pks[n]] })
+	i.primaryKeys[t] = pks
+
+	// 单列主键不一定有对应的索引（ INTEGER PRIMARY KEY 为 rowid 的别名）,
+	// 这里直接标记为唯一。 rowid 别名由 SQLite 自动分配且不能为空，视为自增
+	if len(pks) == 1 {
+		pks[0].Unique = true
+		if strings.EqualFold(pks[0].Type.GetName(), TypeInteger) {
+			pks[0].AutoIncrement = true
+			pks[0].Nullable = false
+		}
+	}
+
+	return columns, nil
+}
+
+func (i *inspect) indexes(ctx context.Context, name string) ([]*schema.Index, error) {
+	rows, err := i.querySqlRows(ctx, IndexesQuery, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var indexs []*schema.Index
+	indexMap := make(map[string]*schema.Index)
+	for rows.Next() {
+		var (
+			unique       bool
+			seqno        int
+			name, origin string
+			column       sql.NullString
+		)
+		if err := rows.Scan(&name, &unique, &origin, &seqno, &column); err != nil {
+			return nil, fmt.Errorf("sqlite/indexes: scanning index: %w", err)
+		}
+
+		index, ok := indexMap[name]
+		if !ok {
+			index = &schema.Index{
+				Name:   name,
+				Unique: unique,
+			}
+			// origin: c (CREATE INDEX), u (UNIQUE 约束), pk (PRIMARY KEY 约束)
+			if origin == "pk" {
+				index.Primary = true
+			}
+
+			indexMap[name] = index
+			indexs = append(indexs, index)
+		}
+
+		index.IndexColumns = append(index.IndexColumns, &schema.IndexColumn{
+			SeqNo:  seqno + 1,
+			Column: column.String,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return indexs, nil
+}
+
+func (i *inspect) foreignKeys(ctx context.Context, t *schema.Table) ([]*schema.ForeignKey, error) {
+	rows, err := i.querySqlRows(ctx, ForeignKeysQuery, t.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var foreignKeys []*schema.ForeignKey
+	foreignKeysMap := make(map[int]*schema.ForeignKey)
+	for rows.Next() {
+		var (
+			id, seq                                  int
+			refTable, column, updateRule, deleteRule string
+			refColumn                                sql.NullString
+		)
+		if err := rows.Scan(&id, &seq, &refTable, &column, &refColumn, &updateRule, &deleteRule); err != nil {
+			return nil, fmt.Errorf("sqlite/fks: scanning fk: %w", err)
+		}
+
+		// SQLite 不保存外键约束名称，按表名和序号生成
+		name := fmt.Sprintf("fk_%s_%d", t.Name, id)
+
+		rt := t.Schema.Table(refTable)
+		if rt == nil {
+			return nil, fmt.Errorf("sqlite/fks: ref table %q not found for fk %q", refTable, name)
+		}
+
+		foreignKey, ok := foreignKeysMap[id]
+		if !ok {
+			foreignKey = &schema.ForeignKey{
+				Name:     name,
+				Table:    t,
+				RefTable: rt,
+				OnUpdate: schema.ReferenceOption(updateRule),
+				OnDelete: schema.ReferenceOption(deleteRule),
+			}
+
+			foreignKeysMap[id] = foreignKey
+			foreignKeys = append(foreignKeys, foreignKey)
+		}
+
+		c := t.Column(column)
+		if c == nil {
+			return nil, fmt.Errorf("sqlite/fks: column %q not found for fk %q", column, foreignKey.Name)
+		}
+		foreignKey.Columns = append(foreignKey.Columns, c)
+
+		// 未指定引用列时，引用父表的主键
+		rc := refPrimaryColumn(i.primaryKeys[rt], seq)
+		if refColumn.Valid {
+			rc = rt.Column(refColumn.String)
+		}
+		if rc == nil {
+			return nil, fmt.Errorf("sqlite/fks: ref column %q not found for fk %q", refColumn.String, foreignKey.Name)
+		}
+		foreignKey.RefColumns = append(foreignKey.RefColumns, rc)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return foreignKeys, nil
+}
+
+// refPrimaryColumn returns the nth column of the primary key in key order.
+func refPrimaryColumn(pks []*schema.Column, n int) *schema.Column {
+	if n < 0 || n >= len(pks) {
+		return nil
+	}
+	return pks[n]
+}
+
+func (i *inspect) querySqlRows(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	result, err := i.Driver.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	rows, ok := result.(*sql.Rows)
+	if !ok {
+		return nil, fmt.Errorf("sqlite: invalid type %T. expect *sql.Rows for result", result)
+	}
+	return rows, nil
+}
+func (i *inspect) querySqlRow(ctx context.Context, query string, args ...any) (*sql.Row, error) {
+
+	result, err := i.Driver.QueryRow(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	row, ok := result.(*sql.Row)
+	if !ok {
+		return nil, fmt.Errorf("sqlite: invalid type %T. expect *sql.Row for result", result)
+	}
+	return row, nil
+}
@@ -0,0 +1,295 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ychengcloud/cre"
+	schema "github.com/ychengcloud/cre/loader/sql"
+	"github.com/ychengcloud/cre/spec"
+
+	_ "modernc.org/sqlite"
+)
+
+type sqliteMock struct {
+	sqlmock.Sqlmock
+}
+
+func (m sqliteMock) info() {
+	m.ExpectQuery(Escape(VersionQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"sqlite_version()"}).
+			AddRow("3.41.2"))
+}
+
+func (m sqliteMock) noIndexes() {
+	m.ExpectQuery(Escape(IndexesQuery)).
+		WillReturnRows(sqlmock.NewRows(IndexesQueryFields))
+}
+
+func (m sqliteMock) noForeignKeys() {
+	m.ExpectQuery(Escape(ForeignKeysQuery)).
+		WillReturnRows(sqlmock.NewRows(ForeignKeysQueryFields))
+}
+
+func TestInspectTable(t *testing.T) {
+	schemaName := "main"
+	tests := []struct {
+		name     string
+		before   func(sqliteMock)
+		expected func() *schema.Schema
+		wantErr  bool
+	}{
+		{
+			name: "no table",
+			before: func(mock sqliteMock) {
+				mock.info()
+				mock.ExpectQuery(Escape(TablesQuery)).
+					WillReturnRows(sqlmock.NewRows(TablesQueryFields))
+			},
+			expected: func() *schema.Schema {
+				return &schema.Schema{
+					Name: schemaName,
+				}
+			},
+		},
+		{
+			name: "columns",
+			before: func(mock sqliteMock) {
+				mock.info()
+				mock.ExpectQuery(Escape(TablesQuery)).
+					WillReturnRows(sqlmock.NewRows(TablesQueryFields).
+						AddRow("table"))
+
+				// cid, name, type, notnull, dflt_value, pk
+				mock.ExpectQuery(Escape(ColumnsQuery)).
+					WithArgs("table").
+					WillReturnRows(sqlmock.NewRows(ColumnsQueryFields).
+						AddRow(0, "id", "INTEGER", 1, nil, 1).
+						AddRow(1, "name", "VARCHAR(64)", 0, "'none'", 0).
+						AddRow(2, "price", "DECIMAL(10,2)", 1, nil, 0).
+						AddRow(3, "created_at", "DATETIME", 1, "CURRENT_TIMESTAMP", 0))
+
+				mock.noIndexes()
+				mock.noForeignKeys()
+			},
+			expected: func() *schema.Schema {
+				s := &schema.Schema{
+					Name: schemaName,
+				}
+				table := &schema.Table{
+					Name:   "table",
+					Schema: s,
+				}
+				table.Columns = []*schema.Column{
+					{Name: "id", Type: &spec.IntegerType{Name: "integer", Size: 64}, Primary: true, Unique: true, AutoIncrement: true, Table: table},
+					{Name: "name", Type: &spec.StringType{Name: "varchar", Size: 64}, Nullable: true, Default: sql.NullString{String: "'none'", Valid: true}, Table: table},
+					{Name: "price", Type: &spec.FloatType{Name: "decimal", Precision: 10, Scale: 2}, Precision: 10, Scale: 2, Table: table},
+					{Name: "created_at", Type: &spec.TimeType{Name: "datetime"}, Default: sql.NullString{String: "CURRENT_TIMESTAMP", Valid: true}, Table: table},
+				}
+				s.Tables = []*schema.Table{table}
+				return s
+			},
+		},
+		{
+			name: "indexes",
+			before: func(mock sqliteMock) {
+				mock.info()
+				mock.ExpectQuery(Escape(TablesQuery)).
+					WillReturnRows(sqlmock.NewRows(TablesQueryFields).
+						AddRow("table"))
+
+				mock.ExpectQuery(Escape(ColumnsQuery)).
+					WithArgs("table").
+					WillReturnRows(sqlmock.NewRows(ColumnsQueryFields).
+						AddRow(0, "code", "TEXT", 1, nil, 1).
+						AddRow(1, "name", "TEXT", 1, nil, 0).
+						AddRow(2, "kind", "TEXT", 1, nil, 0))
+
+				// index_name, unique, origin, seqno, column_name
+				mock.ExpectQuery(Escape(IndexesQuery)).
+					WithArgs("table").
+					WillReturnRows(sqlmock.NewRows(IndexesQueryFields).
+						AddRow("idx_kind", 0, "c", 0, "kind").
+						AddRow("sqlite_autoindex_table_1", 1, "pk", 0, "code").
+						AddRow("sqlite_autoindex_table_2", 1, "u", 0, "name").
+						AddRow("sqlite_autoindex_table_2", 1, "u", 1, "kind"))
+
+				mock.noForeignKeys()
+			},
+			expected: func() *schema.Schema {
+				s := &schema.Schema{
+					Name: schemaName,
+				}
+				table := &schema.Table{
+					Name:   "table",
+					Schema: s,
+				}
+				table.Columns = []*schema.Column{
+					{Name: "code", Type: &spec.StringType{Name: "text"}, Primary: true, Unique: true, Table: table},
+					{Name: "name", Type: &spec.StringType{Name: "text"}, Table: table},
+					{Name: "kind", Type: &spec.StringType{Name: "text"}, Table: table},
+				}
+				table.Indexes = []*schema.Index{
+					{
+						Name:         "idx_kind",
+						IndexColumns: []*schema.IndexColumn{{SeqNo: 1, Column: "kind"}},
+					},
+					{
+						Name:         "sqlite_autoindex_table_1",
+						Unique:       true,
+						Primary:      true,
+						IndexColumns: []*schema.IndexColumn{{SeqNo: 1, Column: "code"}},
+					},
+					{
+						Name:   "sqlite_autoindex_table_2",
+						Unique: true,
+						IndexColumns: []*schema.IndexColumn{
+							{SeqNo: 1, Column: "name"},
+							{SeqNo: 2, Column: "kind"},
+						},
+					},
+				}
+				s.Tables = []*schema.Table{table}
+				return s
+			},
+		},
+		{
+			name: "foreign keys",
+			before: func(mock sqliteMock) {
+				mock.info()
+				mock.ExpectQuery(Escape(TablesQuery)).
+					WillReturnRows(sqlmock.NewRows(TablesQueryFields).
+						AddRow("table").
+						AddRow("fk"))
+
+				mock.ExpectQuery(Escape(ColumnsQuery)).
+					WithArgs("table").
+					WillReturnRows(sqlmock.NewRows(ColumnsQueryFields).
+						AddRow(0, "id", "INTEGER", 1, nil, 1).
+						AddRow(1, "gid", "INTEGER", 1, nil, 0).
+						AddRow(2, "uid", "INTEGER", 0, nil, 0))
+				mock.ExpectQuery(Escape(ColumnsQuery)).
+					WithArgs("fk").
+					WillReturnRows(sqlmock.NewRows(ColumnsQueryFields).
+						AddRow(0, "id", "INTEGER", 1, nil, 1))
+
+				mock.noIndexes()
+				mock.noIndexes()
+
+				// id, seq, table, from, to, on_update, on_delete
+				mock.ExpectQuery(Escape(ForeignKeysQuery)).
+					WithArgs("table").
+					WillReturnRows(sqlmock.NewRows(ForeignKeysQueryFields).
+						AddRow(0, 0, "fk", "gid", nil, "NO ACTION", "CASCADE").
+						AddRow(1, 0, "table", "uid", "id", "NO ACTION", "SET NULL"))
+				mock.ExpectQuery(Escape(ForeignKeysQuery)).
+					WithArgs("fk").
+					WillReturnRows(sqlmock.NewRows(ForeignKeysQueryFields))
+			},
+			expected: func() *schema.Schema {
+				s := &schema.Schema{
+					Name: schemaName,
+				}
+				table := &schema.Table{
+					Name:   "table",
+					Schema: s,
+				}
+				tableFK := &schema.Table{
+					Name:   "fk",
+					Schema: s,
+				}
+				columns := []*schema.Column{
+					{Name: "id", Type: &spec.IntegerType{Name: "integer", Size: 64}, Primary: true, Unique: true, AutoIncrement: true, Table: table},
+					{Name: "gid", Type: &spec.IntegerType{Name: "integer", Size: 64}, Table: table},
+					{Name: "uid", Type: &spec.IntegerType{Name: "integer", Size: 64}, Nullable: true, Table: table},
+				}
+				refColumns := []*schema.Column{
+					{Name: "id", Type: &spec.IntegerType{Name: "integer", Size: 64}, Primary: true, Unique: true, AutoIncrement: true, Table: tableFK},
+				}
+				table.Columns = columns
+				tableFK.Columns = refColumns
+				table.ForeignKeys = []*schema.ForeignKey{
+					{
+						Name:       "fk_table_0",
+						Table:      table,
+						Columns:    columns[1:2],
+						RefTable:   tableFK,
+						RefColumns: refColumns[0:1],
+						OnUpdate:   schema.NoAction,
+						OnDelete:   schema.Cascade,
+					},
+					{
+						Name:       "fk_table_1",
+						Table:      table,
+						Columns:    columns[2:3],
+						RefTable:   table,
+						RefColumns: columns[0:1],
+						OnUpdate:   schema.NoAction,
+						OnDelete:   schema.SetNull,
+					},
+				}
+				s.Tables = []*schema.Table{table, tableFK}
+				return s
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			test.before(sqliteMock{mock})
+			l := &inspect{
+				Driver: schema.OpenDB(cre.SQLite, db),
+			}
+
+			s, err := l.Inspect(context.Background(), schemaName)
+			require.Equal(t, test.wantErr, err != nil, err)
+			require.EqualValues(t, test.expected(), s)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// 未指定引用列的外键按主键中的顺序引用，而不是列的声明顺序，sqlmock 无法覆盖该情况
+func TestInspectForeignKeyPrimaryKeyOrder(t *testing.T) {
+	r := require.New(t)
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	r.NoError(err)
+	defer db.Close()
+	_, err = db.Exec(`
+CREATE TABLE org (b TEXT NOT NULL, a TEXT NOT NULL, PRIMARY KEY (a, b));
+CREATE TABLE member (id INTEGER PRIMARY KEY, oa TEXT, ob TEXT, FOREIGN KEY (oa, ob) REFERENCES org);
+`)
+	r.NoError(err)
+
+	l := &inspect{Driver: schema.OpenDB(cre.SQLite, db)}
+	s, err := l.Inspect(context.Background(), "main")
+	r.NoError(err)
+
+	fks := s.Table("member").ForeignKeys
+	r.Len(fks, 1)
+	var columns, refColumns []string
+	for i := range fks[0].Columns {
+		columns = append(columns, fks[0].Columns[i].Name)
+		refColumns = append(refColumns, fks[0].RefColumns[i].Name)
+	}
+	r.Equal([]string{"oa", "ob"}, columns)
+	r.Equal([]string{"a", "b"}, refColumns)
+}
+
+func Escape(query string) string {
+	rows := strings.Split(query, "\n")
+	for i := range rows {
+		rows[i] = strings.TrimPrefix(rows[i], " ")
+	}
+	query = strings.Join(rows, " ")
+	return strings.TrimSpace(regexp.QuoteMeta(query)) + "$"
+}
@@ -0,0 +1,149 @@
+package sqlite
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ychengcloud/cre/spec"
+)
+
+// parseTypeAttrs splits the declared column type into the type name and the
+// arguments inside the parentheses.
+// 返回值全部为小写格式
+// eg: VARCHAR(255) => varchar, [255]
+//
+//	DECIMAL(10, 2) => decimal, [10 2]
+func parseTypeAttrs(colDef string) (colType string, ext []string) {
+	colDef = strings.ToLower(strings.TrimSpace(colDef))
+
+	before, after, found := strings.Cut(colDef, "(")
+	colType = strings.Join(strings.Fields(before), " ")
+	if !found {
+		return
+	}
+
+	args, _, _ := strings.Cut(after, ")")
+	ext = strings.FieldsFunc(args, func(c rune) bool {
+		return c == ',' || c == ' '
+	})
+	return
+}
+
+// parseSize parses the first argument of the declared type as size.
+func parseSize(ext []string) (int, error) {
+	if len(ext) == 0 {
+		return 0, nil
+	}
+	size, err := strconv.ParseInt(ext[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size: %v", ext[0])
+	}
+	return int(size), nil
+}
+
+// parseInteger parses the column type with INTEGER affinity.
+func parseInteger(colType string, ext []string) (spec.Type, error) {
+	t := &spec.IntegerType{
+		Name:     colType,
+		Unsigned: strings.Contains(colType, "unsigned"),
+	}
+
+	switch colType {
+	case TypeTinyInt:
+		size, err := parseSize(ext)
+		if err != nil {
+			return nil, err
+		}
+		if size == 1 {
+			return &spec.BoolType{Name: colType}, nil
+		}
+		t.Size = 8
+	case TypeSmallInt, TypeInt2:
+		t.Size = 16
+	case TypeMediumInt, TypeInt, TypeInt4:
+		t.Size = 32
+	default:
+		// integer, bigint, int8, unsigned big int ...
+		// SQLite stores integers in up to 8 bytes.
+		t.Size = 64
+	}
+
+	return t, nil
+}
+
+// parseFloat parses the column type with REAL or NUMERIC affinity.
+func parseFloat(colType string, ext []string, precision int) (spec.Type, error) {
+	t := &spec.FloatType{
+		Name:      colType,
+		Precision: precision,
+	}
+
+	if len(ext) > 0 {
+		p, err := strconv.ParseInt(ext[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("float: invalid precision: %v", ext[0])
+		}
+		t.Precision = int(p)
+	}
+	if len(ext) > 1 {
+		scale, err := strconv.ParseInt(ext[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("float: invalid scale: %v", ext[1])
+		}
+		t.Scale = int(scale)
+	}
+	return t, nil
+}
+
+// parseString parses the column type with TEXT affinity.
+func parseString(colType string, ext []string) (spec.Type, error) {
+	size, err := parseSize(ext)
+	if err != nil {
+		return nil, err
+	}
+	return &spec.StringType{Name: colType, Size: size}, nil
+}
+
+// ParseType parses the declared column type and maps it onto spec types.
+// The well-known type names are matched first, the rest are resolved by the
+// SQLite type affinity rules.
+// https://www.sqlite.org/datatype3.html#determination_of_column_affinity
+func ParseType(colDef string) (spec.Type, error) {
+	colType, ext := parseTypeAttrs(colDef)
+
+	switch colType {
+	case TypeBoolean, TypeBool:
+		return &spec.BoolType{Name: colType}, nil
+	case TypeDate, TypeTime, TypeDateTime, TypeTimestamp:
+		return &spec.TimeType{Name: colType}, nil
+	case TypeJSON:
+		return &spec.JSONType{Name: colType}, nil
+	case TypeUUID:
+		return &spec.StringType{Name: colType}, nil
+	case TypeNumeric, TypeDecimal:
+		return parseFloat(colType, ext, 0)
+	}
+
+	switch {
+	case strings.Contains(colType, "int"):
+		return parseInteger(colType, ext)
+	case strings.Contains(colType, "char"), strings.Contains(colType, "clob"), strings.Contains(colType, "text"):
+		return parseString(colType, ext)
+	case colType == "", strings.Contains(colType, "blob"):
+		if colType == "" {
+			colType = TypeBlob
+		}
+		size, err := parseSize(ext)
+		if err != nil {
+			return nil, err
+		}
+		return &spec.BinaryType{Name: colType, Size: size}, nil
+	case strings.Contains(colType, "real"), strings.Contains(colType, "floa"), strings.Contains(colType, "doub"):
+		// REAL 为 8 字节 IEEE 浮点数
+		return parseFloat(colType, ext, 53)
+	default:
+		// NUMERIC affinity
+		return parseFloat(colType, ext, 0)
+	}
+}
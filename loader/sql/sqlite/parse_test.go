@@ -0,0 +1,55 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ychengcloud/cre/spec"
+)
+
+func TestParseType(t *testing.T) {
+	tests := []struct {
+		colDef   string
+		expected spec.Type
+		wantErr  bool
+	}{
+		{colDef: "INTEGER", expected: &spec.IntegerType{Name: "integer", Size: 64}},
+		{colDef: "INT", expected: &spec.IntegerType{Name: "int", Size: 32}},
+		{colDef: "TINYINT", expected: &spec.IntegerType{Name: "tinyint", Size: 8}},
+		{colDef: "TINYINT(1)", expected: &spec.BoolType{Name: "tinyint"}},
+		{colDef: "SMALLINT", expected: &spec.IntegerType{Name: "smallint", Size: 16}},
+		{colDef: "MEDIUMINT", expected: &spec.IntegerType{Name: "mediumint", Size: 32}},
+		{colDef: "BIGINT", expected: &spec.IntegerType{Name: "bigint", Size: 64}},
+		{colDef: "UNSIGNED BIG INT", expected: &spec.IntegerType{Name: "unsigned big int", Size: 64, Unsigned: true}},
+		{colDef: "INT8", expected: &spec.IntegerType{Name: "int8", Size: 64}},
+		{colDef: "CHARACTER(20)", expected: &spec.StringType{Name: "character", Size: 20}},
+		{colDef: "VARCHAR(255)", expected: &spec.StringType{Name: "varchar", Size: 255}},
+		{colDef: "NATIVE CHARACTER(70)", expected: &spec.StringType{Name: "native character", Size: 70}},
+		{colDef: "TEXT", expected: &spec.StringType{Name: "text"}},
+		{colDef: "CLOB", expected: &spec.StringType{Name: "clob"}},
+		{colDef: "BLOB", expected: &spec.BinaryType{Name: "blob"}},
+		{colDef: "", expected: &spec.BinaryType{Name: "blob"}},
+		{colDef: "REAL", expected: &spec.FloatType{Name: "real", Precision: 53}},
+		{colDef: "DOUBLE PRECISION", expected: &spec.FloatType{Name: "double precision", Precision: 53}},
+		{colDef: "FLOAT", expected: &spec.FloatType{Name: "float", Precision: 53}},
+		{colDef: "NUMERIC", expected: &spec.FloatType{Name: "numeric"}},
+		{colDef: "DECIMAL(10,5)", expected: &spec.FloatType{Name: "decimal", Precision: 10, Scale: 5}},
+		{colDef: "BOOLEAN", expected: &spec.BoolType{Name: "boolean"}},
+		{colDef: "DATE", expected: &spec.TimeType{Name: "date"}},
+		{colDef: "DATETIME", expected: &spec.TimeType{Name: "datetime"}},
+		{colDef: "TIMESTAMP", expected: &spec.TimeType{Name: "timestamp"}},
+		{colDef: "JSON", expected: &spec.JSONType{Name: "json"}},
+		{colDef: "UUID", expected: &spec.StringType{Name: "uuid"}},
+		{colDef: "MONEY", expected: &spec.FloatType{Name: "money"}},
+		{colDef: "VARCHAR(abc)", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.colDef, func(t *testing.T) {
+			actual, err := ParseType(test.colDef)
+			require.Equal(t, test.wantErr, err != nil, err)
+			require.Equal(t, test.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package sqlite
+
+// SQLite data types
+// https://www.sqlite.org/datatype3.html
+const (
+	// SQLite storage classes
+	TypeInteger = "integer"
+	TypeReal    = "real"
+	TypeText    = "text"
+	TypeBlob    = "blob"
+	TypeNumeric = "numeric"
+
+	// SQLite integer type names, all of them have INTEGER affinity.
+	TypeInt       = "int"
+	TypeTinyInt   = "tinyint"
+	TypeSmallInt  = "smallint"
+	TypeMediumInt = "mediumint"
+	TypeBigInt    = "bigint"
+	TypeInt2      = "int2"
+	TypeInt4      = "int4"
+	TypeInt8      = "int8"
+
+	// SQLite type names with NUMERIC affinity that map onto richer spec types.
+	TypeBoolean   = "boolean"
+	TypeBool      = "bool"
+	TypeDecimal   = "decimal"
+	TypeDate      = "date"
+	TypeTime      = "time"
+	TypeDateTime  = "datetime"
+	TypeTimestamp = "timestamp"
+	TypeJSON      = "json"
+	TypeUUID      = "uuid"
+)
+
+var (
+	// VersionQuery 数据库版本查询语句
+	VersionQuery = "SELECT sqlite_version()"
+
+	// TablesQuery 表查询语句
+	TablesQueryFields = []string{"name"}
+	TablesQuery       = "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'"
+
+	// ColumnsQuery 列查询语句
+	ColumnsQueryFields = []string{"cid", "name", "type", "notnull", "dflt_value", "pk"}
+	ColumnsQuery       = "SELECT cid, name, type, \"notnull\", dflt_value, pk FROM pragma_table_info(?) ORDER BY cid"
+
+	// IndexesQuery 索引查询语句
+	IndexesQueryFields = []string{"index_name", "unique", "origin", "seqno", "column_name"}
+	IndexesQuery       = `
+SELECT
+	il.name AS index_name,
+	il."unique",
+	il.origin,
+	ii.seqno,
+	ii.name AS column_name
+FROM
+	pragma_index_list(?) AS il
+	JOIN pragma_index_info(il.name) AS ii
+ORDER BY
+	il.name, ii.seqno
+`
+
+	// ForeignKeysQuery 外键查询语句
+	ForeignKeysQueryFields = []string{"id", "seq", "table", "from", "to", "on_update", "on_delete"}
+	ForeignKeysQuery       = `
+SELECT
+	id,
+	seq,
+	"table",
+	"from",
+	"to",
+	on_update,
+	on_delete
+FROM
+	pragma_foreign_key_list(?)
+ORDER BY
+	id, seq
+`
+)
@@ -0,0 +1,712 @@
+package spec
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SnapshotVersion is the version of the snapshot format written by this package.
+// 格式不兼容的修改需要递增版本号
+const SnapshotVersion = 1
+
+// Snapshot formats.
+const (
+	SnapshotJSON = "json"
+	SnapshotYAML = "yaml"
+)
+
+type (
+	// Snapshot is the serializable form of a schema.
+	// 类型使用 type 字段区分具体实现，表、字段之间的引用均以名称表示
+	Snapshot struct {
+		Version int             `json:"version" yaml:"version"`
+		Schema  *SchemaSnapshot `json:"schema" yaml:"schema"`
+	}
+
+	SchemaSnapshot struct {
+		Name   string           `json:"name,omitempty" yaml:"name,omitempty"`
+		Tables []*TableSnapshot `json:"tables,omitempty" yaml:"tables,omitempty"`
+		Attrs  []*AttrSnapshot  `json:"attrs,omitempty" yaml:"attrs,omitempty"`
+	}
+
+	TableSnapshot struct {
+		Name        string                `json:"name" yaml:"name"`
+		Namespace   string                `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+		Comment     string                `json:"comment,omitempty" yaml:"comment,omitempty"`
+		ID          string                `json:"id,omitempty" yaml:"id,omitempty"`
+		IsJoinTable bool                  `json:"isJoinTable,omitempty" yaml:"isJoinTable,omitempty"`
+		JoinTable   *JoinTableSnapshot    `json:"joinTable,omitempty" yaml:"joinTable,omitempty"`
+		Fields      []*FieldSnapshot      `json:"fields,omitempty" yaml:"fields,omitempty"`
+		Indexes     []*IndexSnapshot      `json:"indexes,omitempty" yaml:"indexes,omitempty"`
+		ForeignKeys []*ForeignKeySnapshot `json:"foreignKeys,omitempty" yaml:"foreignKeys,omitempty"`
+		Attrs       []*AttrSnapshot       `json:"attrs,omitempty" yaml:"attrs,omitempty"`
+	}
+
+	FieldSnapshot struct {
+		Name      string        `json:"name" yaml:"name"`
+		Type      *TypeSnapshot `json:"type,omitempty" yaml:"type,omitempty"`
+		Nullable  bool          `json:"nullable,omitempty" yaml:"nullable,omitempty"`
+		Optional  bool          `json:"optional,omitempty" yaml:"optional,omitempty"`
+		Sensitive bool          `json:"sensitive,omitempty" yaml:"sensitive,omitempty"`
+		Tag       string        `json:"tag,omitempty" yaml:"tag,omitempty"`
+		Comment   string        `json:"comment,omitempty" yaml:"comment,omitempty"`
+		Default   *string       `json:"default,omitempty" yaml:"default,omitempty"`
+		Order     int           `json:"order,omitempty" yaml:"order,omitempty"`
+
+		Alias      string `json:"alias,omitempty" yaml:"alias,omitempty"`
+		Sortable   bool   `json:"sortable,omitempty" yaml:"sortable,omitempty"`
+		Filterable bool   `json:"filterable,omitempty" yaml:"filterable,omitempty"`
+
+		ForeignKey    bool `json:"foreignKey,omitempty" yaml:"foreignKey,omitempty"`
+		PrimaryKey    bool `json:"primaryKey,omitempty" yaml:"primaryKey,omitempty"`
+		Index         bool `json:"index,omitempty" yaml:"index,omitempty"`
+		Unique        bool `json:"unique,omitempty" yaml:"unique,omitempty"`
+		AutoIncrement bool `json:"autoIncrement,omitempty" yaml:"autoIncrement,omitempty"`
+		OnUpdate      bool `json:"onUpdate,omitempty" yaml:"onUpdate,omitempty"`
+		Generated     bool `json:"generated,omitempty" yaml:"generated,omitempty"`
+		Remote        bool `json:"remote,omitempty" yaml:"remote,omitempty"`
+
+		Rel   *RelationSnapshot `json:"rel,omitempty" yaml:"rel,omitempty"`
+		Ops   []string          `json:"ops,omitempty" yaml:"ops,omitempty"`
+		Attrs []*AttrSnapshot   `json:"attrs,omitempty" yaml:"attrs,omitempty"`
+	}
+
+	// TypeSnapshot is the union of all the field types, Type is the discriminator.
+	TypeSnapshot struct {
+		Type      string   `json:"type" yaml:"type"`
+		Name      string   `json:"name,omitempty" yaml:"name,omitempty"`
+		Size      int      `json:"size,omitempty" yaml:"size,omitempty"`
+		Len       int      `json:"len,omitempty" yaml:"len,omitempty"`
+		Unsigned  bool     `json:"unsigned,omitempty" yaml:"unsigned,omitempty"`
+		Precision int      `json:"precision,omitempty" yaml:"precision,omitempty"`
+		Scale     int      `json:"scale,omitempty" yaml:"scale,omitempty"`
+		Charset   string   `json:"charset,omitempty" yaml:"charset,omitempty"`
+		Collation string   `json:"collation,omitempty" yaml:"collation,omitempty"`
+		Values    []string `json:"values,omitempty" yaml:"values,omitempty"`
+		Version   string   `json:"version,omitempty" yaml:"version,omitempty"`
+		Exported  bool     `json:"exported,omitempty" yaml:"exported,omitempty"`
+	}
+
+	RelationSnapshot struct {
+		Type      string             `json:"type" yaml:"type"`
+		Field     string             `json:"field,omitempty" yaml:"field,omitempty"`
+		RefTable  string             `json:"refTable,omitempty" yaml:"refTable,omitempty"`
+		RefField  string             `json:"refField,omitempty" yaml:"refField,omitempty"`
+		JoinTable *JoinTableSnapshot `json:"joinTable,omitempty" yaml:"joinTable,omitempty"`
+		Inverse   bool               `json:"inverse,omitempty" yaml:"inverse,omitempty"`
+		OnDelete  string             `json:"onDelete,omitempty" yaml:"onDelete,omitempty"`
+		OnUpdate  string             `json:"onUpdate,omitempty" yaml:"onUpdate,omitempty"`
+		Attrs     []*AttrSnapshot    `json:"attrs,omitempty" yaml:"attrs,omitempty"`
+	}
+
+	JoinTableSnapshot struct {
+		Name         string `json:"name" yaml:"name"`
+		JoinField    string `json:"joinField,omitempty" yaml:"joinField,omitempty"`
+		JoinRefField string `json:"joinRefField,omitempty" yaml:"joinRefField,omitempty"`
+	}
+
+	IndexSnapshot struct {
+		Name    string               `json:"name,omitempty" yaml:"name,omitempty"`
+		Unique  bool                 `json:"unique,omitempty" yaml:"unique,omitempty"`
+		Primary bool                 `json:"primary,omitempty" yaml:"primary,omitempty"`
+		Type    string               `json:"type,omitempty" yaml:"type,omitempty"`
+		Comment string               `json:"comment,omitempty" yaml:"comment,omitempty"`
+		Parts   []*IndexPartSnapshot `json:"parts" yaml:"parts"`
+	}
+
+	IndexPartSnapshot struct {
+		Field string `json:"field,omitempty" yaml:"field,omitempty"`
+		Expr  string `json:"expr,omitempty" yaml:"expr,omitempty"`
+		Sub   int    `json:"sub,omitempty" yaml:"sub,omitempty"`
+	}
+
+	ForeignKeySnapshot struct {
+		Name      string   `json:"name,omitempty" yaml:"name,omitempty"`
+		Fields    []string `json:"fields" yaml:"fields"`
+		RefTable  string   `json:"refTable" yaml:"refTable"`
+		RefFields []string `json:"refFields" yaml:"refFields"`
+		OnDelete  string   `json:"onDelete,omitempty" yaml:"onDelete,omitempty"`
+		OnUpdate  string   `json:"onUpdate,omitempty" yaml:"onUpdate,omitempty"`
+	}
+
+	// AttrSnapshot keeps the attribute value as is, the value must be serializable.
+	AttrSnapshot struct {
+		Name  string `json:"name" yaml:"name"`
+		Value any    `json:"value,omitempty" yaml:"value,omitempty"`
+	}
+)
+
+// Type discriminators of the snapshot.
+const (
+	snapshotBinary  = "binary"
+	snapshotBit     = "bit"
+	snapshotBool    = "bool"
+	snapshotInteger = "integer"
+	snapshotFloat   = "float"
+	snapshotString  = "string"
+	snapshotEnum    = "enum"
+	snapshotUUID    = "uuid"
+	snapshotTime    = "time"
+	snapshotSpatial = "spatial"
+	snapshotJSON    = "json"
+	snapshotObject  = "object"
+)
+
+// attribute is the attribute restored from a snapshot.
+type attribute struct {
+	name  string
+	value any
+}
+
+func (a *attribute) Name() string {
+	return a.name
+}
+
+func (a *attribute) Value() any {
+	return a.value
+}
+
+// NewSnapshot returns the snapshot of the schema.
+func NewSnapshot(s *Schema) (*Snapshot, error) {
+	ss := &SchemaSnapshot{
+		Name:  s.Name,
+		Attrs: snapshotAttrs(s.Attrs),
+	}
+	for _, t := range s.tables {
+		ts, err := snapshotTable(t)
+		if err != nil {
+			return nil, err
+		}
+		ss.Tables = append(ss.Tables, ts)
+	}
+	return &Snapshot{Version: SnapshotVersion, Schema: ss}, nil
+}
+
+func snapshotTable(t *Table) (*TableSnapshot, error) {
+	ts := &TableSnapshot{
+		Name:        t.Name,
+		Namespace:   t.Namespace,
+		Comment:     t.Comment,
+		IsJoinTable: t.IsJoinTable,
+		JoinTable:   snapshotJoinTable(t.JoinTable),
+		Attrs:       snapshotAttrs(t.Attrs),
+	}
+	if t.ID != nil {
+		ts.ID = t.ID.Name
+	}
+	for _, f := range t.fields {
+		fs, err := snapshotField(f)
+		if err != nil {
+			return nil, fmt.Errorf("snapshot: table %s: %w", t.Name, err)
+		}
+		ts.Fields = append(ts.Fields, fs)
+	}
+	for _, idx := range t.Indexes {
+		ts.Indexes = append(ts.Indexes, snapshotIndex(idx))
+	}
+	for _, fk := range t.ForeignKeys {
+		ts.ForeignKeys = append(ts.ForeignKeys, snapshotForeignKey(fk))
+	}
+	return ts, nil
+}
+
+func snapshotIndex(idx *Index) *IndexSnapshot {
+	is := &IndexSnapshot{
+		Name:    idx.Name,
+		Unique:  idx.Unique,
+		Primary: idx.Primary,
+		Type:    idx.Type,
+		Comment: idx.Comment,
+	}
+	for _, p := range idx.Parts {
+		ps := &IndexPartSnapshot{Expr: p.Expr, Sub: p.Sub}
+		if p.Field != nil {
+			ps.Field = p.Field.Name
+		}
+		is.Parts = append(is.Parts, ps)
+	}
+	return is
+}
+
+func snapshotForeignKey(fk *ForeignKey) *ForeignKeySnapshot {
+	fs := &ForeignKeySnapshot{
+		Name:     fk.Name,
+		OnDelete: string(fk.OnDelete),
+		OnUpdate: string(fk.OnUpdate),
+	}
+	for _, f := range fk.Fields {
+		fs.Fields = append(fs.Fields, f.Name)
+	}
+	if fk.RefTable != nil {
+		fs.RefTable = fk.RefTable.QualifiedName()
+	}
+	for _, f := range fk.RefFields {
+		fs.RefFields = append(fs.RefFields, f.Name)
+	}
+	return fs
+}
+
+func snapshotField(f *Field) (*FieldSnapshot, error) {
+	typ, err := snapshotType(f.Type)
+	if err != nil {
+		return nil, fmt.Errorf("field %s: %w", f.Name, err)
+	}
+	fs := &FieldSnapshot{
+		Name:          f.Name,
+		Type:          typ,
+		Nullable:      f.Nullable,
+		Optional:      f.Optional,
+		Sensitive:     f.Sensitive,
+		Tag:           f.Tag,
+		Comment:       f.Comment,
+		Order:         f.Order,
+		Alias:         f.Alias,
+		Sortable:      f.Sortable,
+		Filterable:    f.Filterable,
+		ForeignKey:    f.ForeignKey,
+		PrimaryKey:    f.PrimaryKey,
+		Index:         f.Index,
+		Unique:        f.Unique,
+		AutoIncrement: f.AutoIncrement,
+		OnUpdate:      f.OnUpdate,
+		Generated:     f.Generated,
+		Remote:        f.Remote,
+		Rel:           snapshotRelation(f.Rel),
+		Attrs:         snapshotAttrs(f.Attrs),
+	}
+	if f.Default.Valid {
+		d := f.Default.String
+		fs.Default = &d
+	}
+	for _, op := range f.Ops {
+		fs.Ops = append(fs.Ops, op.Name())
+	}
+	return fs, nil
+}
+
+func snapshotType(t Type) (*TypeSnapshot, error) {
+	switch t := t.(type) {
+	case nil:
+		return nil, nil
+	case *BinaryType:
+		return &TypeSnapshot{Type: snapshotBinary, Name: t.Name, Size: t.Size}, nil
+	case *BitType:
+		return &TypeSnapshot{Type: snapshotBit, Name: t.Name, Len: t.Len}, nil
+	case *BoolType:
+		return &TypeSnapshot{Type: snapshotBool, Name: t.Name}, nil
+	case *IntegerType:
+		return &TypeSnapshot{Type: snapshotInteger, Name: t.Name, Size: t.Size, Unsigned: t.Unsigned}, nil
+	case *FloatType:
+		return &TypeSnapshot{Type: snapshotFloat, Name: t.Name, Precision: t.Precision, Scale: t.Scale}, nil
+	case *StringType:
+		return &TypeSnapshot{Type: snapshotString, Name: t.Name, Size: t.Size, Charset: t.Charset, Collation: t.Collation}, nil
+	case *EnumType:
+		return &TypeSnapshot{Type: snapshotEnum, Name: t.Name, Values: t.Values}, nil
+	case *UUIDType:
+		return &TypeSnapshot{Type: snapshotUUID, Name: t.Name, Version: t.Version}, nil
+	case *TimeType:
+		return &TypeSnapshot{Type: snapshotTime, Name: t.Name, Size: t.Size}, nil
+	case *SpatialType:
+		return &TypeSnapshot{Type: snapshotSpatial, Name: t.Name}, nil
+	case *JSONType:
+		return &TypeSnapshot{Type: snapshotJSON, Name: t.Name}, nil
+	case *ObjectType:
+		return &TypeSnapshot{Type: snapshotObject, Name: t.Name, Exported: t.Exported}, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %T", t)
+	}
+}
+
+func snapshotRelation(r *Relation) *RelationSnapshot {
+	if r == nil {
+		return nil
+	}
+	rs := &RelationSnapshot{
+		Type:      r.Type.Name(),
+		JoinTable: snapshotJoinTable(r.JoinTable),
+		Inverse:   r.Inverse,
+		OnDelete:  string(r.OnDelete),
+		OnUpdate:  string(r.OnUpdate),
+		Attrs:     snapshotAttrs(r.Attrs),
+	}
+	if r.Field != nil {
+		rs.Field = r.Field.Name
+	}
+	if r.RefTable != nil {
+		rs.RefTable = r.RefTable.QualifiedName()
+	}
+	if r.RefField != nil {
+		rs.RefField = r.RefField.Name
+	}
+	return rs
+}
+
+func snapshotJoinTable(jt *JoinTable) *JoinTableSnapshot {
+	if jt == nil {
+		return nil
+	}
+	js := &JoinTableSnapshot{Name: jt.Name}
+	if jt.JoinField != nil {
+		js.JoinField = jt.JoinField.Name
+	}
+	if jt.JoinRefField != nil {
+		js.JoinRefField = jt.JoinRefField.Name
+	}
+	return js
+}
+
+func snapshotAttrs(attrs []Attribute) []*AttrSnapshot {
+	var as []*AttrSnapshot
+	for _, a := range attrs {
+		as = append(as, &AttrSnapshot{Name: a.Name(), Value: a.Value()})
+	}
+	return as
+}
+
+// Restore restores the schema from the snapshot.
+func (s *Snapshot) Restore() (*Schema, error) {
+	if s.Version < 1 || s.Version > SnapshotVersion {
+		return nil, fmt.Errorf("snapshot: unsupported version %d", s.Version)
+	}
+	if s.Schema == nil {
+		return nil, fmt.Errorf("snapshot: missing schema")
+	}
+
+	r := &restorer{
+		schema:     &Schema{Name: s.Schema.Name, Attrs: restoreAttrs(s.Schema.Attrs)},
+		joinTables: make(map[string]*JoinTable),
+	}
+	// 先还原所有表和字段，再按名称关联
+	for _, ts := range s.Schema.Tables {
+		if err := r.table(ts); err != nil {
+			return nil, err
+		}
+	}
+	for _, ts := range s.Schema.Tables {
+		if err := r.references(ts); err != nil {
+			return nil, err
+		}
+	}
+	return r.schema, nil
+}
+
+type restorer struct {
+	schema     *Schema
+	joinTables map[string]*JoinTable
+}
+
+func (r *restorer) table(ts *TableSnapshot) error {
+	name := QualifiedName(ts.Namespace, ts.Name)
+	for _, t := range r.schema.tables {
+		if t.QualifiedName() == name {
+			return fmt.Errorf("snapshot: duplicate table %s", name)
+		}
+	}
+	t := &Table{
+		Name:        ts.Name,
+		Namespace:   ts.Namespace,
+		Comment:     ts.Comment,
+		IsJoinTable: ts.IsJoinTable,
+		Attrs:       restoreAttrs(ts.Attrs),
+	}
+	for _, fs := range ts.Fields {
+		f, err := restoreField(fs)
+		if err != nil {
+			return fmt.Errorf("snapshot: table %s: %w", ts.Name, err)
+		}
+		t.AddFields(f)
+	}
+	// ID 以快照为准
+	t.ID = nil
+	if ts.ID != "" {
+		if t.ID = t.GetField(ts.ID); t.ID == nil {
+			return fmt.Errorf("snapshot: table %s: id field %s not found", ts.Name, ts.ID)
+		}
+	}
+	for _, is := range ts.Indexes {
+		idx, err := restoreIndex(t, is)
+		if err != nil {
+			return fmt.Errorf("snapshot: table %s: index %s: %w", ts.Name, is.Name, err)
+		}
+		t.AddIndexes(idx)
+	}
+	r.schema.AddTables(t)
+	return nil
+}
+
+func restoreIndex(t *Table, is *IndexSnapshot) (*Index, error) {
+	idx := &Index{
+		Name:    is.Name,
+		Unique:  is.Unique,
+		Primary: is.Primary,
+		Type:    is.Type,
+		Comment: is.Comment,
+	}
+	for _, ps := range is.Parts {
+		p := &IndexPart{Expr: ps.Expr, Sub: ps.Sub}
+		if ps.Field != "" {
+			if p.Field = t.GetField(ps.Field); p.Field == nil {
+				return nil, fmt.Errorf("field %s not found", ps.Field)
+			}
+		}
+		idx.Parts = append(idx.Parts, p)
+	}
+	return idx, nil
+}
+
+func restoreField(fs *FieldSnapshot) (*Field, error) {
+	typ, err := restoreType(fs.Type)
+	if err != nil {
+		return nil, fmt.Errorf("field %s: %w", fs.Name, err)
+	}
+	f := &Field{
+		Name:          fs.Name,
+		Type:          typ,
+		Nullable:      fs.Nullable,
+		Optional:      fs.Optional,
+		Sensitive:     fs.Sensitive,
+		Tag:           fs.Tag,
+		Comment:       fs.Comment,
+		Order:         fs.Order,
+		Alias:         fs.Alias,
+		Sortable:      fs.Sortable,
+		Filterable:    fs.Filterable,
+		ForeignKey:    fs.ForeignKey,
+		PrimaryKey:    fs.PrimaryKey,
+		Index:         fs.Index,
+		Unique:        fs.Unique,
+		AutoIncrement: fs.AutoIncrement,
+		OnUpdate:      fs.OnUpdate,
+		Generated:     fs.Generated,
+		Remote:        fs.Remote,
+		Attrs:         restoreAttrs(fs.Attrs),
+	}
+	if fs.Default != nil {
+		f.Default = sql.NullString{String: *fs.Default, Valid: true}
+	}
+	for _, name := range fs.Ops {
+		op := GetOP(name)
+		if op == Unknown {
+			return nil, fmt.Errorf("field %s: unknown operation %s", fs.Name, name)
+		}
+		f.Ops = append(f.Ops, op)
+	}
+	return f, nil
+}
+
+func restoreType(ts *TypeSnapshot) (Type, error) {
+	if ts == nil {
+		return nil, nil
+	}
+	switch ts.Type {
+	case snapshotBinary:
+		return &BinaryType{Name: ts.Name, Size: ts.Size}, nil
+	case snapshotBit:
+		return &BitType{Name: ts.Name, Len: ts.Len}, nil
+	case snapshotBool:
+		return &BoolType{Name: ts.Name}, nil
+	case snapshotInteger:
+		return &IntegerType{Name: ts.Name, Size: ts.Size, Unsigned: ts.Unsigned}, nil
+	case snapshotFloat:
+		return &FloatType{Name: ts.Name, Precision: ts.Precision, Scale: ts.Scale}, nil
+	case snapshotString:
+		return &StringType{Name: ts.Name, Size: ts.Size, Charset: ts.Charset, Collation: ts.Collation}, nil
+	case snapshotEnum:
+		return &EnumType{Name: ts.Name, Values: ts.Values}, nil
+	case snapshotUUID:
+		return &UUIDType{Name: ts.Name, Version: ts.Version}, nil
+	case snapshotTime:
+		return &TimeType{Name: ts.Name, Size: ts.Size}, nil
+	case snapshotSpatial:
+		return &SpatialType{Name: ts.Name}, nil
+	case snapshotJSON:
+		return &JSONType{Name: ts.Name}, nil
+	case snapshotObject:
+		return &ObjectType{Name: ts.Name, Exported: ts.Exported}, nil
+	default:
+		return nil, fmt.Errorf("unknown type %q", ts.Type)
+	}
+}
+
+// references restores the relations and the join tables of the table.
+func (r *restorer) references(ts *TableSnapshot) error {
+	t := r.schema.Table(QualifiedName(ts.Namespace, ts.Name))
+
+	jt, err := r.joinTable(ts.JoinTable)
+	if err != nil {
+		return fmt.Errorf("snapshot: table %s: %w", ts.Name, err)
+	}
+	t.JoinTable = jt
+
+	for _, fs := range ts.Fields {
+		if fs.Rel == nil {
+			continue
+		}
+		f := t.GetField(fs.Name)
+		if f.Rel, err = r.relation(f, fs.Rel); err != nil {
+			return fmt.Errorf("snapshot: table %s: field %s: %w", ts.Name, fs.Name, err)
+		}
+	}
+
+	for _, fs := range ts.ForeignKeys {
+		fk, err := r.foreignKey(t, fs)
+		if err != nil {
+			return fmt.Errorf("snapshot: table %s: foreign key %s: %w", ts.Name, fs.Name, err)
+		}
+		t.ForeignKeys = append(t.ForeignKeys, fk)
+	}
+	return nil
+}
+
+func (r *restorer) foreignKey(t *Table, fs *ForeignKeySnapshot) (*ForeignKey, error) {
+	fk := &ForeignKey{
+		Name:     fs.Name,
+		OnDelete: ReferenceOption(fs.OnDelete),
+		OnUpdate: ReferenceOption(fs.OnUpdate),
+	}
+	if fk.RefTable = r.schema.Table(fs.RefTable); fk.RefTable == nil {
+		return nil, fmt.Errorf("ref table %s not found", fs.RefTable)
+	}
+	for _, name := range fs.Fields {
+		f := t.GetField(name)
+		if f == nil {
+			return nil, fmt.Errorf("field %s not found", name)
+		}
+		fk.Fields = append(fk.Fields, f)
+	}
+	for _, name := range fs.RefFields {
+		f := fk.RefTable.GetField(name)
+		if f == nil {
+			return nil, fmt.Errorf("ref field %s not found in table %s", name, fs.RefTable)
+		}
+		fk.RefFields = append(fk.RefFields, f)
+	}
+	return fk, nil
+}
+
+func (r *restorer) relation(f *Field, rs *RelationSnapshot) (*Relation, error) {
+	rel := &Relation{
+		Type:     GetRelType(rs.Type),
+		Inverse:  rs.Inverse,
+		OnDelete: ReferenceOption(rs.OnDelete),
+		OnUpdate: ReferenceOption(rs.OnUpdate),
+		Attrs:    restoreAttrs(rs.Attrs),
+	}
+	if rs.Field != "" {
+		if rel.Field = f.Table.GetField(rs.Field); rel.Field == nil {
+			return nil, fmt.Errorf("relation field %s not found", rs.Field)
+		}
+	}
+
+	if rs.RefTable != "" {
+		rel.RefTable = r.schema.Table(rs.RefTable)
+		// 远程表不在 schema 中，与合并配置时的处理一致，仅保留引用字段
+		if rel.RefTable == nil && f.Remote {
+			rel.RefTable = &Table{Name: rs.RefTable}
+			rf := Builder(rs.RefField).Build()
+			rel.RefTable.AddFields(rf)
+			rel.RefTable.ID = rf
+		}
+		if rel.RefTable == nil {
+			return nil, fmt.Errorf("ref table %s not found", rs.RefTable)
+		}
+	}
+	if rs.RefField != "" {
+		if rel.RefTable == nil {
+			return nil, fmt.Errorf("ref field %s without ref table", rs.RefField)
+		}
+		if rel.RefField = rel.RefTable.GetField(rs.RefField); rel.RefField == nil {
+			return nil, fmt.Errorf("ref field %s not found in table %s", rs.RefField, rs.RefTable)
+		}
+	}
+
+	var err error
+	if rel.JoinTable, err = r.joinTable(rs.JoinTable); err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
+
+// joinTable restores the join table, the same join table is shared by the
+// relation and the table itself.
+func (r *restorer) joinTable(js *JoinTableSnapshot) (*JoinTable, error) {
+	if js == nil {
+		return nil, nil
+	}
+	if jt, ok := r.joinTables[js.Name]; ok {
+		return jt, nil
+	}
+
+	jt := &JoinTable{Name: js.Name}
+	t := r.schema.Table(js.Name)
+	if t == nil && (js.JoinField != "" || js.JoinRefField != "") {
+		return nil, fmt.Errorf("join table %s not found", js.Name)
+	}
+	if js.JoinField != "" {
+		if jt.JoinField = t.GetField(js.JoinField); jt.JoinField == nil {
+			return nil, fmt.Errorf("join field %s not found in table %s", js.JoinField, js.Name)
+		}
+	}
+	if js.JoinRefField != "" {
+		if jt.JoinRefField = t.GetField(js.JoinRefField); jt.JoinRefField == nil {
+			return nil, fmt.Errorf("join ref field %s not found in table %s", js.JoinRefField, js.Name)
+		}
+	}
+	r.joinTables[js.Name] = jt
+	return jt, nil
+}
+
+func restoreAttrs(as []*AttrSnapshot) []Attribute {
+	var attrs []Attribute
+	for _, a := range as {
+		attrs = append(attrs, &attribute{name: a.Name, value: a.Value})
+	}
+	return attrs
+}
+
+// MarshalSnapshot encodes the schema snapshot in the given format (json or yaml).
+func MarshalSnapshot(s *Schema, format string) ([]byte, error) {
+	snapshot, err := NewSnapshot(s)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(format) {
+	case SnapshotJSON:
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(snapshot); err != nil {
+			return nil, fmt.Errorf("snapshot: %w", err)
+		}
+		return buf.Bytes(), nil
+	case SnapshotYAML, "yml":
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(snapshot); err != nil {
+			return nil, fmt.Errorf("snapshot: %w", err)
+		}
+		if err := enc.Close(); err != nil {
+			return nil, fmt.Errorf("snapshot: %w", err)
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("snapshot: unsupported format %q", format)
+	}
+}
+
+// UnmarshalSnapshot decodes the schema from a JSON or YAML snapshot.
+// JSON 是 YAML 的子集，统一按 YAML 解析
+func UnmarshalSnapshot(data []byte) (*Schema, error) {
+	snapshot := &Snapshot{}
+	if err := yaml.Unmarshal(data, snapshot); err != nil {
+		return nil, fmt.Errorf("snapshot: %w", err)
+	}
+	return snapshot.Restore()
+}
@@ -0,0 +1,124 @@
+package spec
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func snapshotSchema() *Schema {
+	user := &Table{Name: "user", Comment: "users", Attrs: []Attribute{&attribute{name: "plural", value: "users"}}}
+	post := &Table{Name: "post"}
+	tag := &Table{Name: "tag", Namespace: "catalog"}
+	postTag := &Table{Name: "post_tag", IsJoinTable: true}
+
+	user.AddFields(
+		Builder("id").Type(&IntegerType{Name: "bigint", Size: 64, Unsigned: true}).PrimaryKey(true).Unique(true).AutoIncrement(true).Build(),
+		Builder("name").Type(&StringType{Name: "varchar", Size: 64, Charset: "utf8mb4", Collation: "utf8mb4_bin"}).Comment("name").Tag(`validate:"required"`).Build(),
+		Builder("mood").Type(&EnumType{Name: "enum", Values: []string{"a", "b"}}).Nullable(true).Default(sql.NullString{String: "a", Valid: true}).Build(),
+		Builder("avatar").Type(&BinaryType{Name: "blob"}).Sensitive(true).Build(),
+		Builder("flags").Type(&BitType{Name: "bit", Len: 8}).Build(),
+		Builder("active").Type(&BoolType{Name: "tinyint"}).Default(sql.NullString{String: "", Valid: true}).Build(),
+		Builder("score").Type(&FloatType{Name: "decimal", Precision: 10, Scale: 2}).Alias("points").Sortable(true).Build(),
+		Builder("uuid").Type(&UUIDType{Name: "uuid", Version: "v4"}).Build(),
+		Builder("created_at").Type(&TimeType{Name: "datetime", Size: 6}).OnUpdate(true).Build(),
+		Builder("name_length").Type(&IntegerType{Name: "int", Size: 32}).Generated(true).Build(),
+		Builder("location").Type(&SpatialType{Name: "point"}).Build(),
+		Builder("profile").Type(&JSONType{Name: "json"}).Optional(true).Build(),
+		Builder("extra").Type(&ObjectType{Name: "Extra", Exported: true}).Ops([]Op{Eq, In}).Build(),
+	)
+	post.AddFields(
+		Builder("id").Type(&IntegerType{Name: "bigint", Size: 64}).PrimaryKey(true).Unique(true).Build(),
+		Builder("user_id").Type(&IntegerType{Name: "bigint", Size: 64}).ForeignKey(true).Index(true).Build(),
+		Builder("author_id").Type(&IntegerType{Name: "bigint", Size: 64}).Remote(true).Build(),
+	)
+	tag.AddFields(Builder("id").Type(&IntegerType{Name: "int", Size: 32}).PrimaryKey(true).Unique(true).Build())
+	postTag.AddFields(
+		Builder("post_id").Type(&IntegerType{Name: "bigint", Size: 64}).PrimaryKey(true).Build(),
+		Builder("tag_id").Type(&IntegerType{Name: "int", Size: 32}).PrimaryKey(true).Build(),
+	)
+
+	// relations
+	userPosts := Builder("posts").Type(&ObjectType{Name: "post"}).Build()
+	userPosts.Rel = &Relation{Type: RelTypeHasMany, Field: user.GetField("id"), RefTable: post, RefField: post.GetField("user_id"), Attrs: []Attribute{&attribute{name: "preload", value: true}}}
+	user.AddFields(userPosts)
+
+	postUser := Builder("user").Type(&ObjectType{Name: "user"}).Build()
+	postUser.Rel = &Relation{Type: RelTypeBelongsTo, Field: post.GetField("user_id"), RefTable: user, RefField: user.GetField("id"), Inverse: true, OnDelete: Cascade, OnUpdate: NoAction}
+
+	author := &Table{Name: "author"}
+	authorID := Builder("id").Build()
+	author.AddFields(authorID)
+	author.ID = authorID
+	postAuthor := Builder("author").Remote(true).Build()
+	postAuthor.Rel = &Relation{Type: RelTypeBelongsTo, Field: post.GetField("author_id"), RefTable: author, RefField: authorID}
+
+	jt := &JoinTable{Name: "post_tag", JoinField: postTag.GetField("post_id"), JoinRefField: postTag.GetField("tag_id")}
+	postTags := Builder("tags").Type(&ObjectType{Name: "tag"}).Build()
+	postTags.Rel = &Relation{Type: RelTypeManyToMany, Field: post.GetField("id"), RefTable: tag, RefField: tag.GetField("id"), JoinTable: jt}
+	postTag.JoinTable = jt
+	post.AddFields(postUser, postAuthor, postTags)
+	user.AddIndexes(
+		&Index{Name: "PRIMARY", Unique: true, Primary: true, Type: "BTREE", Parts: []*IndexPart{{Field: user.GetField("id")}}},
+		&Index{Name: "uniq_name_mood", Unique: true, Type: "BTREE", Comment: "name", Parts: []*IndexPart{{Field: user.GetField("name"), Sub: 16}, {Field: user.GetField("mood")}}},
+		&Index{Name: "idx_lower_name", Type: "BTREE", Parts: []*IndexPart{{Expr: "lower(`name`)"}}},
+	)
+	postTag.AddIndexes(&Index{Name: "post_tag_pkey", Unique: true, Primary: true, Parts: []*IndexPart{{Field: postTag.GetField("tag_id")}, {Field: postTag.GetField("post_id")}}})
+	post.ForeignKeys = []*ForeignKey{{Name: "post_user_id_fkey", Fields: []*Field{post.GetField("user_id")}, RefTable: user, RefFields: []*Field{user.GetField("id")}, OnDelete: Cascade, OnUpdate: NoAction}}
+	postTag.ForeignKeys = []*ForeignKey{
+		{Name: "post_tag_post_id_fkey", Fields: []*Field{postTag.GetField("post_id")}, RefTable: post, RefFields: []*Field{post.GetField("id")}},
+		{Fields: []*Field{postTag.GetField("tag_id")}, RefTable: tag, RefFields: []*Field{tag.GetField("id")}},
+	}
+
+	s := &Schema{Name: "test", Attrs: []Attribute{&attribute{name: "version", value: "v1"}}}
+	s.AddTables(user, post, tag, postTag)
+	return s
+}
+
+func TestSnapshot(t *testing.T) {
+	for _, format := range []string{SnapshotJSON, SnapshotYAML} {
+		t.Run(format, func(t *testing.T) {
+			expected := snapshotSchema()
+			data, err := MarshalSnapshot(expected, format)
+			require.NoError(t, err)
+
+			actual, err := UnmarshalSnapshot(data)
+			require.NoError(t, err)
+			require.Equal(t, expected, actual)
+
+			// 重复快照结果稳定
+			again, err := MarshalSnapshot(actual, format)
+			require.NoError(t, err)
+			require.Equal(t, string(data), string(again))
+		})
+	}
+}
+
+func TestSnapshotErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "unsupported version", data: `{"version": 2, "schema": {"name": "test"}}`},
+		{name: "missing schema", data: `{"version": 1}`},
+		{name: "unknown type", data: `{"version": 1, "schema": {"tables": [{"name": "t", "fields": [{"name": "id", "type": {"type": "money"}}]}]}}`},
+		{name: "unknown op", data: `{"version": 1, "schema": {"tables": [{"name": "t", "fields": [{"name": "id", "ops": ["Like"]}]}]}}`},
+		{name: "id not found", data: `{"version": 1, "schema": {"tables": [{"name": "t", "id": "id"}]}}`},
+		{name: "ref table not found", data: `{"version": 1, "schema": {"tables": [{"name": "t", "fields": [{"name": "id"}, {"name": "u", "rel": {"type": "BelongsTo", "field": "id", "refTable": "user"}}]}]}}`},
+		{name: "fk ref table not found", data: `{"version": 1, "schema": {"tables": [{"name": "t", "fields": [{"name": "id"}], "foreignKeys": [{"fields": ["id"], "refTable": "user", "refFields": ["id"]}]}]}}`},
+		{name: "fk field not found", data: `{"version": 1, "schema": {"tables": [{"name": "t", "fields": [{"name": "id"}], "foreignKeys": [{"fields": ["uid"], "refTable": "t", "refFields": ["id"]}]}]}}`},
+		{name: "index field not found", data: `{"version": 1, "schema": {"tables": [{"name": "t", "indexes": [{"name": "idx", "parts": [{"field": "id"}]}]}]}}`},
+		{name: "duplicate table", data: `{"version": 1, "schema": {"tables": [{"name": "t"}, {"name": "t"}]}}`},
+		{name: "invalid data", data: `{"version": `},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := UnmarshalSnapshot([]byte(test.data))
+			require.Error(t, err)
+		})
+	}
+
+	_, err := MarshalSnapshot(&Schema{}, "xml")
+	require.Error(t, err)
+}
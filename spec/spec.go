@@ -17,6 +17,17 @@ const (
 	RelTypeManyToMany
 )
 
+// ReferenceOption represents the referential action of ON UPDATE and ON DELETE.
+type ReferenceOption string
+
+const (
+	NoAction   ReferenceOption = "NO ACTION"
+	Restrict   ReferenceOption = "RESTRICT"
+	Cascade    ReferenceOption = "CASCADE"
+	SetNull    ReferenceOption = "SET NULL"
+	SetDefault ReferenceOption = "SET DEFAULT"
+)
+
 type Op int
 
 const (
@@ -122,12 +133,25 @@ type (
 		Name    string
 		Comment string
 		fields  []*Field
-		ID      *Field
+		ID      *Field // ID 单列主键，复合主键时为 nil
 		Attrs   []Attribute
 
+		// Namespace 表所在的数据库 schema，如 Postgres 的 billing，为空时表示默认 schema
+		Namespace string
+
+		// PrimaryKey 主键字段，有主键索引时按索引中的顺序，否则按字段顺序
+		PrimaryKey []*Field
+
 		IsJoinTable bool
 		JoinTable   *JoinTable
 
+		// Indexes 表的所有索引（包括主键），UniqueConstraints 为其中的非主键唯一索引
+		Indexes           []*Index
+		UniqueConstraints []*Index
+
+		// ForeignKeys 数据库中定义的外键，用于推断关联关系
+		ForeignKeys []*ForeignKey
+
 		Schema *Schema
 	}
 
@@ -153,6 +177,7 @@ type (
 		Unique        bool `json:"unique,omitempty"`
 		AutoIncrement bool `json:"autoIncrement,omitempty"`
 		OnUpdate      bool `json:"onUpdate,omitempty"`
+		Generated     bool `json:"generated,omitempty"`
 		Remote        bool `json:"remote,omitempty"`
 
 		Rel   *Relation `json:"rel,omitempty"`
@@ -171,6 +196,10 @@ type (
 		JoinTable *JoinTable  `json:"join_table,omitempty"`
 		Inverse   bool        `json:"inverse,omitempty"`
 		Attrs     []Attribute `json:"attrs,omitempty"`
+
+		// OnDelete, OnUpdate 外键的引用动作，没有对应外键时为空
+		OnDelete ReferenceOption `json:"on_delete,omitempty"`
+		OnUpdate ReferenceOption `json:"on_update,omitempty"`
 	}
 
 	JoinTable struct {
@@ -178,6 +207,35 @@ type (
 		JoinField    *Field `json:"join_field,omitempty"`
 		JoinRefField *Field `json:"join_ref_field,omitempty"`
 	}
+
+	// Index represents an index definition.
+	Index struct {
+		Name    string       `json:"name,omitempty"`
+		Unique  bool         `json:"unique,omitempty"`
+		Primary bool         `json:"primary,omitempty"`
+		Type    string       `json:"type,omitempty"`
+		Comment string       `json:"comment,omitempty"`
+		Parts   []*IndexPart `json:"parts,omitempty"`
+	}
+
+	// IndexPart represents a column or an expression of the index, 按索引中的顺序排列.
+	IndexPart struct {
+		Field *Field `json:"field,omitempty"` // 表达式索引时为 nil
+		Expr  string `json:"expr,omitempty"`
+		Sub   int    `json:"sub,omitempty"` // 前缀索引长度
+	}
+
+	// ForeignKey represents a foreign key definition.
+	ForeignKey struct {
+		Name      string   `json:"name,omitempty"`
+		Fields    []*Field `json:"fields,omitempty"`
+		RefTable  *Table   `json:"ref_table,omitempty"`
+		RefFields []*Field `json:"ref_fields,omitempty"`
+
+		OnDelete ReferenceOption `json:"on_delete,omitempty"`
+		OnUpdate ReferenceOption `json:"on_update,omitempty"`
+	}
+
 	// Attribute represents an attribute definition.
 	Attribute interface {
 		Name() string
@@ -287,6 +345,11 @@ func (fb *FieldBuilder) OnUpdate(onUpdate bool) *FieldBuilder {
 	return fb
 }
 
+func (fb *FieldBuilder) Generated(generated bool) *FieldBuilder {
+	fb.field.Generated = generated
+	return fb
+}
+
 func (fb *FieldBuilder) Remote(remote bool) *FieldBuilder {
 	fb.field.Remote = remote
 	return fb
@@ -321,14 +384,43 @@ func (s *Schema) Tables() []*Table {
 	return s.tables
 }
 
-// Table return the table with the given name.
+// Table return the table with the given name, name 可以是带 schema 前缀的限定名称，如 billing.invoice
+// 不同 schema 中存在同名表时，未限定的名称返回第一个
 func (s *Schema) Table(name string) *Table {
+	var found *Table
 	for _, t := range s.tables {
-		if t.Name == name {
+		if t.QualifiedName() == name {
 			return t
 		}
+		if found == nil && t.Name == name {
+			found = t
+		}
 	}
-	return nil
+	return found
+}
+
+// Namespaces returns the namespaces of the tables in order of appearance.
+func (s *Schema) Namespaces() []string {
+	var namespaces []string
+	seen := make(map[string]bool)
+	for _, t := range s.tables {
+		if !seen[t.Namespace] {
+			seen[t.Namespace] = true
+			namespaces = append(namespaces, t.Namespace)
+		}
+	}
+	return namespaces
+}
+
+// NamespaceTables returns the tables in the given namespace.
+func (s *Schema) NamespaceTables(namespace string) []*Table {
+	tables := make([]*Table, 0)
+	for _, t := range s.tables {
+		if t.Namespace == namespace {
+			tables = append(tables, t)
+		}
+	}
+	return tables
 }
 
 // Tables 返回非关联表的 Table 列表
@@ -374,13 +466,28 @@ func (s *Schema) AddTables(tables ...*Table) {
 
 // RemoveTable removes the table with the given name.
 func (s *Schema) RemoveTable(name string) {
+	table := s.Table(name)
 	for i, t := range s.tables {
-		if t.Name == name {
+		if t == table {
 			s.tables = append(s.tables[:i], s.tables[i+1:]...)
+			return
 		}
 	}
 }
 
+// QualifiedName returns the name with the namespace prefix, eg: billing.invoice
+func (t *Table) QualifiedName() string {
+	return QualifiedName(t.Namespace, t.Name)
+}
+
+// QualifiedName joins the namespace and the table name, 未设置 namespace 时仅返回表名
+func QualifiedName(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "." + name
+}
+
 func (t *Table) Fields() []*Field {
 	return t.fields
 }
@@ -426,10 +533,13 @@ func defaultOps(t Type, nullable bool) (ops []Op) {
 // AddField adds a new field to the table.
 func (t *Table) AddFields(fields ...*Field) {
 	for _, f := range fields {
-		// 主键且唯一即为 ID 字段（复合主键按约定视为关联表主键）
+		// 主键且唯一即为 ID 字段，复合主键的各字段均不唯一
 		if f.PrimaryKey && f.Unique {
 			t.ID = f
 		}
+		if f.PrimaryKey {
+			t.PrimaryKey = append(t.PrimaryKey, f)
+		}
 		f.Table = t
 		t.fields = append(t.fields, f)
 	}
@@ -452,6 +562,79 @@ func (t *Table) RemoveField(name string) {
 			t.fields = append(t.fields[:i], t.fields[i+1:]...)
 		}
 	}
+	for i, f := range t.PrimaryKey {
+		if f.Name == name {
+			t.PrimaryKey = append(t.PrimaryKey[:i], t.PrimaryKey[i+1:]...)
+		}
+	}
+	if t.ID != nil && t.ID.Name == name {
+		t.ID = nil
+	}
+
+	// 包含该字段的索引和外键一并移除
+	indexes := t.Indexes
+	t.Indexes, t.UniqueConstraints = nil, nil
+	for _, idx := range indexes {
+		if !idx.hasField(name) {
+			t.AddIndexes(idx)
+		}
+	}
+	fks := t.ForeignKeys[:0]
+	for _, fk := range t.ForeignKeys {
+		if !hasField(fk.Fields, name) {
+			fks = append(fks, fk)
+		}
+	}
+	t.ForeignKeys = fks
+}
+
+func (idx *Index) hasField(name string) bool {
+	for _, p := range idx.Parts {
+		if p.Field != nil && p.Field.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasField(fields []*Field, name string) bool {
+	for _, f := range fields {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// AddIndexes adds the indexes to the table, 非主键的唯一索引同时加入 UniqueConstraints.
+func (t *Table) AddIndexes(indexes ...*Index) {
+	for _, idx := range indexes {
+		t.Indexes = append(t.Indexes, idx)
+		// 复合主键的顺序以主键索引为准，如 PRIMARY KEY (b, a)
+		if fields := idx.Fields(); idx.Primary && len(fields) > 0 {
+			t.PrimaryKey = fields
+		}
+		if idx.Unique && !idx.Primary {
+			t.UniqueConstraints = append(t.UniqueConstraints, idx)
+		}
+	}
+}
+
+// Fields returns the fields of the index, 包含表达式时返回 nil.
+func (idx *Index) Fields() []*Field {
+	fields := make([]*Field, 0, len(idx.Parts))
+	for _, p := range idx.Parts {
+		if p.Field == nil {
+			return nil
+		}
+		fields = append(fields, p.Field)
+	}
+	return fields
+}
+
+// CompositeKey returns true if the primary key of the table has more than one field.
+func (t *Table) CompositeKey() bool {
+	return len(t.PrimaryKey) > 1
 }
 
 // HasFilterField returns true if any field is filterable
@@ -33,3 +33,90 @@ func TestDefaultOps(t *testing.T) {
 	ops = defaultOps(name.Type, name.Optional)
 	r.Equal(len(StringOps), len(ops))
 }
+
+func TestPrimaryKey(t *testing.T) {
+	r := require.New(t)
+
+	user := &Table{Name: "user"}
+	user.AddFields(
+		Builder("id").Type(&IntegerType{Name: "int", Size: 32}).PrimaryKey(true).Unique(true).Build(),
+		Builder("name").Type(&StringType{Name: "char", Size: 64}).Build(),
+	)
+	r.Equal(user.GetField("id"), user.ID)
+	r.Equal([]*Field{user.GetField("id")}, user.PrimaryKey)
+	r.False(user.CompositeKey())
+
+	member := &Table{Name: "member"}
+	member.AddFields(
+		Builder("org_id").Type(&IntegerType{Name: "int", Size: 32}).PrimaryKey(true).Build(),
+		Builder("name").Type(&StringType{Name: "char", Size: 64}).Build(),
+		Builder("user_id").Type(&IntegerType{Name: "int", Size: 32}).PrimaryKey(true).Build(),
+	)
+	r.Nil(member.ID)
+	r.Equal([]*Field{member.GetField("org_id"), member.GetField("user_id")}, member.PrimaryKey)
+	r.True(member.CompositeKey())
+
+	// 主键索引中的顺序与字段顺序不同
+	member.AddIndexes(&Index{Name: "PRIMARY", Unique: true, Primary: true, Parts: []*IndexPart{
+		{Field: member.GetField("user_id")}, {Field: member.GetField("org_id")},
+	}})
+	r.Equal([]*Field{member.GetField("user_id"), member.GetField("org_id")}, member.PrimaryKey)
+
+	member.RemoveField("org_id")
+	r.Equal([]*Field{member.GetField("user_id")}, member.PrimaryKey)
+
+	user.RemoveField("id")
+	r.Nil(user.ID)
+	r.Empty(user.PrimaryKey)
+}
+
+func TestIndexes(t *testing.T) {
+	r := require.New(t)
+
+	user := &Table{Name: "user"}
+	user.AddFields(
+		Builder("id").Type(&IntegerType{Name: "int", Size: 32}).PrimaryKey(true).Unique(true).Build(),
+		Builder("org_id").Type(&IntegerType{Name: "int", Size: 32}).Build(),
+		Builder("email").Type(&StringType{Name: "varchar", Size: 64}).Build(),
+	)
+	id, org, email := user.GetField("id"), user.GetField("org_id"), user.GetField("email")
+
+	pk := &Index{Name: "PRIMARY", Unique: true, Primary: true, Parts: []*IndexPart{{Field: id}}}
+	uniq := &Index{Name: "uniq_org_email", Unique: true, Parts: []*IndexPart{{Field: org}, {Field: email}}}
+	expr := &Index{Name: "idx_lower_email", Parts: []*IndexPart{{Expr: "lower(email)"}}}
+	user.AddIndexes(pk, uniq, expr)
+
+	r.Equal([]*Index{pk, uniq, expr}, user.Indexes)
+	r.Equal([]*Index{uniq}, user.UniqueConstraints)
+	r.Equal([]*Field{org, email}, uniq.Fields())
+	r.Nil(expr.Fields())
+
+	user.RemoveField("email")
+	r.Equal([]*Index{pk, expr}, user.Indexes)
+	r.Empty(user.UniqueConstraints)
+}
+
+func TestNamespaces(t *testing.T) {
+	r := require.New(t)
+
+	authUser := &Table{Name: "user", Namespace: "auth"}
+	billingUser := &Table{Name: "user", Namespace: "billing"}
+	invoice := &Table{Name: "invoice", Namespace: "billing"}
+	s := &Schema{Name: "test"}
+	s.AddTables(authUser, billingUser, invoice)
+
+	r.Equal("billing.user", billingUser.QualifiedName())
+	r.Equal("user", (&Table{Name: "user"}).QualifiedName())
+
+	r.Equal(billingUser, s.Table("billing.user"))
+	// 未限定的名称返回第一个同名表
+	r.Equal(authUser, s.Table("user"))
+	r.Equal(invoice, s.Table("invoice"))
+	r.Nil(s.Table("auth.invoice"))
+
+	r.Equal([]string{"auth", "billing"}, s.Namespaces())
+	r.Equal([]*Table{billingUser, invoice}, s.NamespaceTables("billing"))
+
+	s.RemoveTable("billing.user")
+	r.Equal([]*Table{authUser, invoice}, s.Tables())
+}